@@ -1,11 +1,54 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"sync/atomic"
+
+	"github.com/aramirez3/chirpy/internal/database"
+)
+
+const (
+	devPlatform = "dev"
 )
 
+// resetTables clears every table during POST /admin/reset.
+// New tables must be added here, with child tables before their parents.
+var resetTables = []func(*database.Queries, context.Context) error{
+	(*database.Queries).DeleteAllFilterWords,
+	(*database.Queries).DeleteAllLinkPreviews,
+	(*database.Queries).DeleteAllRateLimitBuckets,
+	(*database.Queries).DeleteAllIdempotencyKeys,
+	(*database.Queries).DeleteAllStreamEvents,
+	(*database.Queries).DeleteAllWebhookEvents,
+	(*database.Queries).DeleteAllWebhookDeliveries,
+	(*database.Queries).DeleteAllWebhookEndpoints,
+	(*database.Queries).DeleteAllSubscriptionEvents,
+	(*database.Queries).DeleteAllSubscriptions,
+	(*database.Queries).DeleteAllMessages,
+	(*database.Queries).DeleteAllScheduledChirps,
+	(*database.Queries).DeleteAllBookmarks,
+	(*database.Queries).DeleteAllListMembers,
+	(*database.Queries).DeleteAllLists,
+	(*database.Queries).DeleteAllPollVotes,
+	(*database.Queries).DeleteAllPollOptions,
+	(*database.Queries).DeleteAllPolls,
+	(*database.Queries).DeleteAllMedia,
+	(*database.Queries).DeleteAllConversations,
+	(*database.Queries).DeleteAllNotifications,
+	(*database.Queries).DeleteAllNotificationPreferences,
+	(*database.Queries).DeleteAllLikes,
+	(*database.Queries).DeleteAllRechirps,
+	(*database.Queries).DeleteAllFollows,
+	(*database.Queries).DeleteAllBlocks,
+	(*database.Queries).DeleteAllMutes,
+	(*database.Queries).DeleteAllModerationActions,
+	(*database.Queries).DeleteAllReports,
+	(*database.Queries).DeleteAllRefreshTokens,
+	(*database.Queries).DeleteAllChirps,
+	(*database.Queries).DeleteAllUsers,
+}
+
 func (cfg *apiConfig) handlerMetrics(w http.ResponseWriter, req *http.Request) {
 	w.Header().Add(contentType, textHtmlContentType)
 
@@ -35,21 +78,49 @@ func (cfg *apiConfig) handlerMetrics(w http.ResponseWriter, req *http.Request) {
 }
 
 func (cfg *apiConfig) handleReset(w http.ResponseWriter, req *http.Request) {
-	cfg.fileServerHits = atomic.Int32{}
 	w.Header().Add(contentType, plainTextContentType)
+	if cfg.Platform != devPlatform {
+		returnForbidden(w)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	for _, deleteAll := range resetTables {
+		err = deleteAll(qtx, req.Context())
+		if err != nil {
+			returnErrorResponse(w, standardError)
+			return
+		}
+	}
+
+	if req.URL.Query().Get("seed") == "true" {
+		err = seedFixtures(req.Context(), qtx)
+		if err != nil {
+			returnErrorResponse(w, standardError)
+			return
+		}
+	}
 
-	err := cfg.dbQueries.DeleteAllUsers(req.Context())
+	err = tx.Commit()
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
 	}
 
-	err = cfg.dbQueries.DeleteAllChirps(req.Context())
+	err = cfg.loadProfanityFilter(req.Context())
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
 	}
 
+	cfg.fileServerHits.Store(0)
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(http.StatusText(http.StatusOK)))
 }
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/aramirez3/chirpy/internal/filter"
+	"github.com/google/uuid"
+)
+
+type FilterWord struct {
+	Word   string        `json:"word"`
+	Action filter.Action `json:"action"`
+}
+
+type FilterWordRequest struct {
+	Action string `json:"action"`
+}
+
+var defaultProfaneWords = map[string]filter.Action{
+	"kerfuffle": filter.Mask,
+	"sharbert":  filter.Mask,
+	"fornax":    filter.Mask,
+}
+
+// filterAllow is stored for words taken off the default or file word list,
+// which can't be deleted from there.
+const filterAllow = "allow"
+
+// filterReloadInterval is how long other instances take to pick up word list
+// changes made through the admin API.
+const filterReloadInterval = time.Minute
+
+// loadProfanityFilter rebuilds the word list from PROFANITY_FILE (or the
+// defaults) with the words managed through the admin API layered on top.
+func (cfg *apiConfig) loadProfanityFilter(ctx context.Context) error {
+	words, err := cfg.baseFilterWords()
+	if err != nil {
+		return err
+	}
+	dbWords, err := cfg.dbQueries.GetFilterWords(ctx)
+	if err != nil {
+		return err
+	}
+	cfg.profanity.Replace(mergeFilterWords(words, dbWords))
+	return nil
+}
+
+// baseFilterWords is the word list before admin changes: PROFANITY_FILE, or
+// the defaults without one.
+func (cfg *apiConfig) baseFilterWords() (map[string]filter.Action, error) {
+	if cfg.ProfanityFile != "" {
+		return filter.LoadFile(cfg.ProfanityFile)
+	}
+	words := map[string]filter.Action{}
+	for word, action := range defaultProfaneWords {
+		words[word] = action
+	}
+	return words, nil
+}
+
+func (cfg *apiConfig) runFilterReload(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := cfg.loadProfanityFilter(ctx)
+			if err != nil {
+				log.Printf("error reloading profanity filter: %s\n", err)
+			}
+		}
+	}
+}
+
+func mergeFilterWords(words map[string]filter.Action, dbWords []database.FilterWord) map[string]filter.Action {
+	for _, w := range dbWords {
+		if w.Action == filterAllow {
+			delete(words, w.Word)
+			continue
+		}
+		words[w.Word] = filter.Action(w.Action)
+	}
+	return words
+}
+
+func (cfg *apiConfig) flagChirpForReview(ctx context.Context, chirpId uuid.UUID, matches []string) error {
+	now := time.Now().UTC()
+	_, err := cfg.dbQueries.CreateReport(ctx, database.CreateReportParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		ChirpID:   uuid.NullUUID{UUID: chirpId, Valid: true},
+		Reason:    reportReasonFilter,
+		Details:   "Matched: " + strings.Join(matches, ", "),
+	})
+	return err
+}
+
+func (cfg *apiConfig) handleGetFilterWords(w http.ResponseWriter, req *http.Request) {
+	_, ok := cfg.authenticateModerator(w, req)
+	if !ok {
+		return
+	}
+
+	words := cfg.profanity.Words()
+	response := []FilterWord{}
+	for _, word := range filter.SortedWords(words) {
+		response = append(response, FilterWord{
+			Word:   word,
+			Action: words[word],
+		})
+	}
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleUpsertFilterWord(w http.ResponseWriter, req *http.Request) {
+	_, ok := cfg.authenticateModerator(w, req)
+	if !ok {
+		return
+	}
+
+	word := filter.Normalize(req.PathValue("word"))
+	if word == "" || strings.ContainsFunc(word, unicode.IsSpace) {
+		returnErrorResponse(w, "Invalid word")
+		return
+	}
+
+	payload := FilterWordRequest{}
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	action, err := filter.ParseAction(payload.Action)
+	if err != nil {
+		returnErrorResponse(w, "Invalid filter action")
+		return
+	}
+
+	now := time.Now().UTC()
+	dbWord, err := cfg.dbQueries.UpsertFilterWord(req.Context(), database.UpsertFilterWordParams{
+		Word:      word,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Action:    string(action),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = cfg.loadProfanityFilter(req.Context())
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(FilterWord{
+		Word:   dbWord.Word,
+		Action: filter.Action(dbWord.Action),
+	})
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleDeleteFilterWord(w http.ResponseWriter, req *http.Request) {
+	_, ok := cfg.authenticateModerator(w, req)
+	if !ok {
+		return
+	}
+
+	word := filter.Normalize(req.PathValue("word"))
+	if _, ok := cfg.profanity.Words()[word]; !ok {
+		returnNotFound(w)
+		return
+	}
+	base, err := cfg.baseFilterWords()
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	// Words from the defaults or PROFANITY_FILE stay off the list with an
+	// allow override; words added through the API are simply deleted.
+	if _, ok := base[word]; ok {
+		now := time.Now().UTC()
+		_, err = cfg.dbQueries.UpsertFilterWord(req.Context(), database.UpsertFilterWordParams{
+			Word:      word,
+			CreatedAt: now,
+			UpdatedAt: now,
+			Action:    filterAllow,
+		})
+	} else {
+		_, err = cfg.dbQueries.DeleteFilterWord(req.Context(), word)
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = cfg.loadProfanityFilter(req.Context())
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/aramirez3/chirpy/internal/filter"
+)
+
+func TestMergeFilterWords(t *testing.T) {
+	words := map[string]filter.Action{"kerfuffle": filter.Mask, "sharbert": filter.Mask}
+	merged := mergeFilterWords(words, []database.FilterWord{
+		{Word: "kerfuffle", Action: filterAllow},
+		{Word: "sharbert", Action: string(filter.Reject)},
+		{Word: "fornax", Action: string(filter.Flag)},
+	})
+	expected := map[string]filter.Action{"sharbert": filter.Reject, "fornax": filter.Flag}
+	if len(merged) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, merged)
+	}
+	for word, action := range expected {
+		if merged[word] != action {
+			t.Errorf("%s: expected %s, got %s", word, action, merged[word])
+		}
+	}
+}
+
+func TestFileFilterWordsCanBeAllowed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	err := os.WriteFile(path, []byte("Kerfuffle\nFÓRNAX flag\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &apiConfig{ProfanityFile: path}
+	words, err := cfg.baseFilterWords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// DELETE looks words up by their normalized form.
+	for _, word := range []string{filter.Normalize("KERFUFFLE"), filter.Normalize("fórnax")} {
+		if _, ok := words[word]; !ok {
+			t.Fatalf("expected %q in the file words, got %v", word, words)
+		}
+	}
+
+	merged := mergeFilterWords(words, []database.FilterWord{
+		{Word: filter.Normalize("Kerfuffle"), Action: filterAllow},
+	})
+	if _, ok := merged["kerfuffle"]; ok || merged["fornax"] != filter.Flag {
+		t.Errorf("expected only fornax to stay on the list, got %v", merged)
+	}
+}
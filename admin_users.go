@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type AccountStatus struct {
+	Id             uuid.UUID  `json:"id"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	BannedAt       *time.Time `json:"banned_at,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+}
+
+type ModeratorStatus struct {
+	Id          uuid.UUID `json:"id"`
+	IsModerator bool      `json:"is_moderator"`
+}
+
+type SuspensionRequest struct {
+	Reason string `json:"reason"`
+	Hours  int    `json:"hours"`
+}
+
+const (
+	moderationSuspendUser     = "suspend_user"
+	moderationUnsuspendUser   = "unsuspend_user"
+	moderationBanUser         = "ban_user"
+	moderationGrantModerator  = "grant_moderator"
+	moderationRevokeModerator = "revoke_moderator"
+
+	defaultSuspension = 7 * 24 * time.Hour
+)
+
+func suspensionDuration(hours int) time.Duration {
+	if hours > 0 {
+		return time.Duration(hours) * time.Hour
+	}
+	return defaultSuspension
+}
+
+// suspendUser disables the account until now+duration and revokes its refresh tokens.
+func suspendUser(ctx context.Context, qtx *database.Queries, userId uuid.UUID, duration time.Duration, reason string) (database.User, error) {
+	now := time.Now().UTC()
+	dbUser, err := qtx.SuspendUser(ctx, database.SuspendUserParams{
+		ID:               userId,
+		SuspendedUntil:   sql.NullTime{Time: now.Add(duration), Valid: true},
+		SuspensionReason: reason,
+		UpdatedAt:        now,
+	})
+	if err != nil {
+		return dbUser, err
+	}
+	return dbUser, revokeUserRefreshTokens(ctx, qtx, userId)
+}
+
+// banUser disables the account indefinitely and revokes its refresh tokens.
+func banUser(ctx context.Context, qtx *database.Queries, userId uuid.UUID, reason string) (database.User, error) {
+	now := time.Now().UTC()
+	dbUser, err := qtx.BanUser(ctx, database.BanUserParams{
+		ID:               userId,
+		BannedAt:         sql.NullTime{Time: now, Valid: true},
+		SuspensionReason: reason,
+		UpdatedAt:        now,
+	})
+	if err != nil {
+		return dbUser, err
+	}
+	return dbUser, revokeUserRefreshTokens(ctx, qtx, userId)
+}
+
+func revokeUserRefreshTokens(ctx context.Context, qtx *database.Queries, userId uuid.UUID) error {
+	now := time.Now().UTC()
+	return qtx.RevokeUserRefreshTokens(ctx, database.RevokeUserRefreshTokensParams{
+		UserID:    userId,
+		UpdatedAt: now,
+		RevokedAt: sql.NullTime{
+			Time:  now,
+			Valid: true,
+		},
+	})
+}
+
+func (cfg *apiConfig) handleSuspendUser(w http.ResponseWriter, req *http.Request) {
+	cfg.updateAccountStatus(w, req, moderationSuspendUser)
+}
+
+func (cfg *apiConfig) handleUnsuspendUser(w http.ResponseWriter, req *http.Request) {
+	cfg.updateAccountStatus(w, req, moderationUnsuspendUser)
+}
+
+func (cfg *apiConfig) handleBanUser(w http.ResponseWriter, req *http.Request) {
+	cfg.updateAccountStatus(w, req, moderationBanUser)
+}
+
+func (cfg *apiConfig) handleGrantModerator(w http.ResponseWriter, req *http.Request) {
+	cfg.setModerator(w, req, true)
+}
+
+func (cfg *apiConfig) handleRevokeModerator(w http.ResponseWriter, req *http.Request) {
+	cfg.setModerator(w, req, false)
+}
+
+// setModerator grants or takes away the moderator role. Moderators manage each
+// other, and ADMIN_KEY appoints the first moderators of a new deployment.
+// Changes made with ADMIN_KEY are logged with the nil UUID as the moderator.
+func (cfg *apiConfig) setModerator(w http.ResponseWriter, req *http.Request, isModerator bool) {
+	moderatorId := uuid.Nil
+	if !cfg.isAdminKey(req) {
+		moderator, ok := cfg.authenticateModerator(w, req)
+		if !ok {
+			return
+		}
+		moderatorId = moderator.ID
+	}
+
+	userId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, "Invalid user id")
+		return
+	}
+	if userId == moderatorId {
+		returnErrorResponse(w, "Cannot change your own moderator role")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	dbUser, err := qtx.SetUserModerator(req.Context(), database.SetUserModeratorParams{
+		ID:          userId,
+		IsModerator: isModerator,
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		returnNotFound(w)
+		return
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	action := moderationRevokeModerator
+	if isModerator {
+		action = moderationGrantModerator
+	}
+	err = recordModerationAction(req.Context(), qtx, database.CreateModerationActionParams{
+		ModeratorID: moderatorId,
+		Action:      action,
+		UserID:      uuid.NullUUID{UUID: userId, Valid: true},
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(ModeratorStatus{Id: dbUser.ID, IsModerator: dbUser.IsModerator})
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) updateAccountStatus(w http.ResponseWriter, req *http.Request, action string) {
+	moderator, ok := cfg.authenticateModerator(w, req)
+	if !ok {
+		return
+	}
+
+	userId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if userId == moderator.ID {
+		returnErrorResponse(w, "Cannot change your own account status")
+		return
+	}
+	_, err = cfg.dbQueries.GetUserById(req.Context(), userId)
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+
+	payload := SuspensionRequest{}
+	if action != moderationUnsuspendUser {
+		err = json.NewDecoder(req.Body).Decode(&payload)
+		if err != nil || payload.Reason == "" {
+			returnErrorResponse(w, "A reason is required")
+			return
+		}
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	var dbUser database.User
+	switch action {
+	case moderationSuspendUser:
+		dbUser, err = suspendUser(req.Context(), qtx, userId, suspensionDuration(payload.Hours), payload.Reason)
+	case moderationBanUser:
+		dbUser, err = banUser(req.Context(), qtx, userId, payload.Reason)
+	default:
+		dbUser, err = qtx.UnsuspendUser(req.Context(), database.UnsuspendUserParams{
+			ID:        userId,
+			UpdatedAt: time.Now().UTC(),
+		})
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	err = recordModerationAction(req.Context(), qtx, database.CreateModerationActionParams{
+		ModeratorID: moderator.ID,
+		Action:      action,
+		UserID:      uuid.NullUUID{UUID: userId, Valid: true},
+		Notes:       payload.Reason,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(toAccountStatus(dbUser))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func toAccountStatus(u database.User) AccountStatus {
+	status := AccountStatus{
+		Id:     u.ID,
+		Reason: u.SuspensionReason,
+	}
+	if u.SuspendedUntil.Valid {
+		status.SuspendedUntil = &u.SuspendedUntil.Time
+	}
+	if u.BannedAt.Valid {
+		status.BannedAt = &u.BannedAt.Time
+	}
+	return status
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSetModeratorRequiresAdminKeyOrModerator(t *testing.T) {
+	s, ts := testServer(t)
+	s.Config.AdminKey = "admin-key"
+
+	cases := []struct {
+		method        string
+		authorization string
+		id            string
+		expected      int
+	}{
+		{http.MethodPut, "", "not-a-uuid", http.StatusUnauthorized},
+		{http.MethodPut, "ApiKey wrong-key", "not-a-uuid", http.StatusUnauthorized},
+		{http.MethodDelete, "Bearer not-a-jwt", "not-a-uuid", http.StatusUnauthorized},
+		// The admin key gets past authentication to the id check, which
+		// comes before any database access.
+		{http.MethodPut, "ApiKey admin-key", "not-a-uuid", http.StatusBadRequest},
+		{http.MethodDelete, "ApiKey admin-key", "not-a-uuid", http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, ts.URL+"/admin/users/"+c.id+"/moderator", nil)
+		if c.authorization != "" {
+			req.Header.Set("Authorization", c.authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", c.method, req.URL, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != c.expected {
+			t.Errorf("%s with %q: expected %d, got %d", c.method, c.authorization, c.expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestIsAdminKeyDisabledWithoutKey(t *testing.T) {
+	cfg := &createServer("0").Config
+	req, _ := http.NewRequest(http.MethodPut, "/admin/users/x/moderator", nil)
+	req.Header.Set("Authorization", "ApiKey ")
+	if cfg.isAdminKey(req) {
+		t.Error("expected no key to match when ADMIN_KEY is unset")
+	}
+	req.Header.Set("Authorization", "ApiKey anything")
+	if cfg.isAdminKey(req) {
+		t.Error("expected no key to match when ADMIN_KEY is unset")
+	}
+}
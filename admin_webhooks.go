@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type WebhookEvent struct {
+	Id          uuid.UUID       `json:"id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Source      string          `json:"source"`
+	DeliveryId  string          `json:"delivery_id"`
+	Event       string          `json:"event"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Error       string          `json:"error,omitempty"`
+	Attempts    int32           `json:"attempts"`
+	ProcessedAt *time.Time      `json:"processed_at,omitempty"`
+}
+
+func (cfg *apiConfig) handleGetWebhookEvents(w http.ResponseWriter, req *http.Request) {
+	_, ok := cfg.authenticateModerator(w, req)
+	if !ok {
+		return
+	}
+
+	limit, err := queryLimit(req, defaultListLimit, maxListLimit)
+	if err != nil {
+		returnErrorResponse(w, "Invalid limit")
+		return
+	}
+
+	var dbEvents []database.WebhookEvent
+	status := req.URL.Query().Get("status")
+	if status == "" {
+		dbEvents, err = cfg.dbQueries.GetWebhookEvents(req.Context(), limit)
+	} else {
+		dbEvents, err = cfg.dbQueries.GetWebhookEventsByStatus(req.Context(), database.GetWebhookEventsByStatusParams{
+			Status: status,
+			Limit:  limit,
+		})
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	response := []WebhookEvent{}
+	for _, e := range dbEvents {
+		response = append(response, dbWebhookEventToResponse(e))
+	}
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleGetWebhookEvent(w http.ResponseWriter, req *http.Request) {
+	_, ok := cfg.authenticateModerator(w, req)
+	if !ok {
+		return
+	}
+
+	eventId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	dbEvent, err := cfg.dbQueries.GetWebhookEventById(req.Context(), eventId)
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+
+	respBody, _ := encodeJson(dbWebhookEventToResponse(dbEvent))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// handleReplayWebhookEvent runs a stored delivery again, whatever its status.
+// The result is recorded on the event, so failures are reported in the body
+// rather than as an error status.
+func (cfg *apiConfig) handleReplayWebhookEvent(w http.ResponseWriter, req *http.Request) {
+	_, ok := cfg.authenticateModerator(w, req)
+	if !ok {
+		return
+	}
+
+	eventId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	_, err = cfg.dbQueries.IncrementWebhookEventAttempts(req.Context(), database.IncrementWebhookEventAttemptsParams{
+		ID:        eventId,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+
+	_, err = cfg.processPolkaEvent(req.Context(), eventId, true)
+	dbEvent, getErr := cfg.dbQueries.GetWebhookEventById(req.Context(), eventId)
+	if getErr != nil || (err != nil && dbEvent.Status != webhookEventFailed) {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(dbWebhookEventToResponse(dbEvent))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func dbWebhookEventToResponse(e database.WebhookEvent) WebhookEvent {
+	response := WebhookEvent{
+		Id:         e.ID,
+		CreatedAt:  e.CreatedAt,
+		Source:     e.Source,
+		DeliveryId: e.DeliveryID,
+		Event:      e.Event,
+		Payload:    json.RawMessage(e.Payload),
+		Status:     e.Status,
+		Error:      e.Error,
+		Attempts:   e.Attempts,
+	}
+	if e.ProcessedAt.Valid {
+		response.ProcessedAt = &e.ProcessedAt.Time
+	}
+	return response
+}
@@ -2,14 +2,17 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"database/sql"
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/aramirez3/chirpy/internal/auth"
 	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
 )
 
 type RefreshTokenResponse struct {
@@ -62,6 +65,16 @@ func (cfg *apiConfig) handleRefresh(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	dbUser, err := cfg.dbQueries.GetUserById(req.Context(), dbToken.UserID)
+	if err != nil {
+		returnUnauthorized(w)
+		return
+	}
+	if isAccountDisabled(dbUser) {
+		returnForbidden(w)
+		return
+	}
+
 	jwt, err := auth.MakeJWT(dbToken.UserID, cfg.Secret, time.Hour)
 	if err != nil {
 		returnErrorResponse(w, standardError)
@@ -100,6 +113,10 @@ func (cfg *apiConfig) handleLogin(w http.ResponseWriter, req *http.Request) {
 		returnUnauthorized(w)
 		return
 	}
+	if isAccountDisabled(dbUser) {
+		returnForbidden(w)
+		return
+	}
 
 	jwt, err := auth.MakeJWT(dbUser.ID, cfg.Secret, time.Hour)
 	if err != nil {
@@ -173,3 +190,84 @@ func (cfg *apiConfig) handleRevoke(w http.ResponseWriter, req *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// authenticateRequest validates the bearer JWT and loads its user. It writes a
+// 401 when the token is missing or invalid and a 403 when the account is disabled.
+func (cfg *apiConfig) authenticateRequest(w http.ResponseWriter, req *http.Request) (database.User, bool) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		returnUnauthorized(w)
+		return database.User{}, false
+	}
+	return cfg.authenticateToken(w, req, token)
+}
+
+func (cfg *apiConfig) authenticateToken(w http.ResponseWriter, req *http.Request, token string) (database.User, bool) {
+	jwtId, err := auth.ValidateJWT(token, cfg.Secret)
+	if err != nil {
+		returnUnauthorized(w)
+		return database.User{}, false
+	}
+
+	dbUser, err := cfg.dbQueries.GetUserById(req.Context(), jwtId)
+	if err != nil {
+		returnUnauthorized(w)
+		return database.User{}, false
+	}
+	if isAccountDisabled(dbUser) {
+		returnForbidden(w)
+		return database.User{}, false
+	}
+	return dbUser, true
+}
+
+// bearerUserId reads the user from a request's access token without loading
+// them, for middleware that only needs to tell callers apart.
+func (cfg *apiConfig) bearerUserId(req *http.Request) (uuid.UUID, bool) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	userId, err := auth.ValidateJWT(token, cfg.Secret)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return userId, true
+}
+
+// optionalViewer identifies the caller on endpoints that also serve anonymous
+// requests, returning uuid.Nil when there is no bearer token. A token that
+// doesn't validate is still rejected.
+func (cfg *apiConfig) optionalViewer(w http.ResponseWriter, req *http.Request) (uuid.UUID, bool) {
+	if req.Header.Get("Authorization") == "" {
+		return uuid.Nil, true
+	}
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return uuid.Nil, false
+	}
+	return dbUser.ID, true
+}
+
+// isAdminKey reports whether the request carries ADMIN_KEY as
+// "Authorization: ApiKey <key>". Without ADMIN_KEY set, nothing matches.
+func (cfg *apiConfig) isAdminKey(req *http.Request) bool {
+	if cfg.AdminKey == "" || !strings.HasPrefix(req.Header.Get("Authorization"), "ApiKey ") {
+		return false
+	}
+	key, err := auth.GetAPIKey(req.Header)
+	return err == nil && subtle.ConstantTimeCompare([]byte(key), []byte(cfg.AdminKey)) == 1
+}
+
+// authenticateModerator is authenticateRequest plus a 403 for users without the moderator role.
+func (cfg *apiConfig) authenticateModerator(w http.ResponseWriter, req *http.Request) (database.User, bool) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return database.User{}, false
+	}
+	if !dbUser.IsModerator {
+		returnForbidden(w)
+		return database.User{}, false
+	}
+	return dbUser, true
+}
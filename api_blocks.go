@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/chirptext"
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type BlockedUser struct {
+	UserId    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// handleBlockUser blocks the user in the path and removes any follows
+// between the two users.
+func (cfg *apiConfig) handleBlockUser(w http.ResponseWriter, req *http.Request) {
+	dbUser, targetId, ok := cfg.authenticateUserAction(w, req)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	_, err = qtx.BlockUser(req.Context(), database.BlockUserParams{
+		BlockerID: dbUser.ID,
+		BlockedID: targetId,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = qtx.DeleteFollowsBetween(req.Context(), database.DeleteFollowsBetweenParams{
+		UserID:  dbUser.ID,
+		OtherID: targetId,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUnblockUser(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	targetId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	_, err = cfg.dbQueries.UnblockUser(req.Context(), database.UnblockUserParams{
+		BlockerID: dbUser.ID,
+		BlockedID: targetId,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleMuteUser(w http.ResponseWriter, req *http.Request) {
+	dbUser, targetId, ok := cfg.authenticateUserAction(w, req)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.dbQueries.MuteUser(req.Context(), database.MuteUserParams{
+		MuterID:   dbUser.ID,
+		MutedID:   targetId,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUnmuteUser(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	targetId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	_, err = cfg.dbQueries.UnmuteUser(req.Context(), database.UnmuteUserParams{
+		MuterID: dbUser.ID,
+		MutedID: targetId,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetBlockedUsers(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	dbBlocks, err := cfg.dbQueries.GetBlockedUsers(req.Context(), dbUser.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	response := []BlockedUser{}
+	for _, b := range dbBlocks {
+		response = append(response, BlockedUser{UserId: b.BlockedID, CreatedAt: b.CreatedAt})
+	}
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleGetMutedUsers(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	dbMutes, err := cfg.dbQueries.GetMutedUsers(req.Context(), dbUser.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	response := []BlockedUser{}
+	for _, m := range dbMutes {
+		response = append(response, BlockedUser{UserId: m.MutedID, CreatedAt: m.CreatedAt})
+	}
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+const errMentionsBlocker = "You can't mention users who blocked you"
+
+// mentionsBlocker reports whether body mentions anyone who blocked authorId.
+// Blocked users can't mention the people who blocked them.
+func mentionsBlocker(ctx context.Context, q *database.Queries, authorId uuid.UUID, body string) (bool, error) {
+	emails := chirptext.Mentions(body)
+	if len(emails) == 0 {
+		return false, nil
+	}
+	return q.IsBlockedByAnyEmail(ctx, database.IsBlockedByAnyEmailParams{
+		UserID: authorId,
+		Emails: emails,
+	})
+}
+
+// checkMentions writes a 400 when body mentions someone who blocked authorId.
+func (cfg *apiConfig) checkMentions(w http.ResponseWriter, req *http.Request, authorId uuid.UUID, body string) bool {
+	blocked, err := mentionsBlocker(req.Context(), cfg.dbQueries, authorId, body)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return false
+	}
+	if blocked {
+		returnErrorResponse(w, errMentionsBlocker)
+		return false
+	}
+	return true
+}
+
+// authenticateUserAction loads the caller and checks that the user in the
+// path exists and isn't the caller.
+func (cfg *apiConfig) authenticateUserAction(w http.ResponseWriter, req *http.Request) (database.User, uuid.UUID, bool) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return database.User{}, uuid.Nil, false
+	}
+	targetId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return database.User{}, uuid.Nil, false
+	}
+	if targetId == dbUser.ID {
+		returnErrorResponse(w, "You can't do that to yourself")
+		return database.User{}, uuid.Nil, false
+	}
+	_, err = cfg.dbQueries.GetUserById(req.Context(), targetId)
+	if err != nil {
+		returnNotFound(w)
+		return database.User{}, uuid.Nil, false
+	}
+	return dbUser, targetId, true
+}
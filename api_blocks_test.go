@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBlockedUsersCantMentionTheBlocker(t *testing.T) {
+	s, ts := testDBServer(t)
+	blocker, blockerToken := createTestUser(t, s)
+	blocked, blockedToken := createTestUser(t, s)
+	other, _ := createTestUser(t, s)
+
+	status := doRequest(t, http.MethodPost, ts.URL+"/api/users/"+blocked.ID.String()+"/block", blockerToken, nil, nil)
+	if status >= 300 {
+		t.Fatalf("block: expected success, got %d", status)
+	}
+
+	status = doRequest(t, http.MethodPost, ts.URL+"/api/chirps", blockedToken, ChirpRequest{Body: "hey @" + blocker.Email}, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("mentioning the blocker: expected 400, got %d", status)
+	}
+	postChirp(t, ts, blockedToken, ChirpRequest{Body: "hey @" + other.Email})
+	postChirp(t, ts, blockerToken, ChirpRequest{Body: "hey @" + blocked.Email})
+}
+
+// feedFor returns the ids in the global feed as token's user sees it.
+func feedFor(t *testing.T, url, token string) []uuid.UUID {
+	t.Helper()
+	chirps := []Chirp{}
+	status := doRequest(t, http.MethodGet, url+"/api/chirps", token, nil, &chirps)
+	if status != http.StatusOK {
+		t.Fatalf("GET /api/chirps: expected 200, got %d", status)
+	}
+	return chirpIds(chirps)
+}
+
+func TestBlockedUsersLoseAccessToTheBlocker(t *testing.T) {
+	s, ts := testDBServer(t)
+	blocker, blockerToken := createTestUser(t, s)
+	blocked, blockedToken := createTestUser(t, s)
+	chirp := postChirp(t, ts, blockerToken, ChirpRequest{Body: "not for you"})
+	doRequest(t, http.MethodPost, ts.URL+"/api/users/"+blocked.ID.String()+"/block", blockerToken, nil, nil)
+
+	if ids := feedFor(t, ts.URL, blockedToken); slices.Contains(ids, chirp.Id) {
+		t.Error("expected the blocker's chirp to be missing from the blocked user's feed")
+	}
+	if status := doRequest(t, http.MethodGet, ts.URL+"/api/chirps/"+chirp.Id.String(), blockedToken, nil, nil); status != http.StatusNotFound {
+		t.Errorf("viewing the blocker's chirp: expected 404, got %d", status)
+	}
+	status := doRequest(t, http.MethodPost, ts.URL+"/api/chirps", blockedToken, ChirpRequest{Body: "reply", ReplyToId: &chirp.Id}, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("replying to the blocker: expected 400, got %d", status)
+	}
+	if status := doRequest(t, http.MethodPost, ts.URL+"/api/users/"+blocker.ID.String()+"/follow", blockedToken, nil, nil); status != http.StatusForbidden {
+		t.Errorf("following the blocker: expected 403, got %d", status)
+	}
+
+	// Anyone else still sees it.
+	if status := doRequest(t, http.MethodGet, ts.URL+"/api/chirps/"+chirp.Id.String(), "", nil, nil); status != http.StatusOK {
+		t.Errorf("viewing anonymously: expected 200, got %d", status)
+	}
+}
+
+func TestMutedUsersAreHiddenFromTheMuterOnly(t *testing.T) {
+	s, ts := testDBServer(t)
+	muter, muterToken := createTestUser(t, s)
+	muted, mutedToken := createTestUser(t, s)
+	_, otherToken := createTestUser(t, s)
+	chirp := postChirp(t, ts, mutedToken, ChirpRequest{Body: "noise"})
+	own := postChirp(t, ts, muterToken, ChirpRequest{Body: "signal"})
+	doRequest(t, http.MethodPost, ts.URL+"/api/users/"+muted.ID.String()+"/mute", muterToken, nil, nil)
+
+	if ids := feedFor(t, ts.URL, muterToken); slices.Contains(ids, chirp.Id) || !slices.Contains(ids, own.Id) {
+		t.Errorf("expected only the muter's own chirp in their feed, got %v", ids)
+	}
+	if ids := feedFor(t, ts.URL, otherToken); !slices.Contains(ids, chirp.Id) {
+		t.Error("expected the muted user's chirp in other feeds")
+	}
+
+	// Muting is silent: the muted user can still interact, without notifying.
+	if status := doRequest(t, http.MethodPost, ts.URL+"/api/chirps/"+own.Id.String()+"/like", mutedToken, nil, nil); status >= 300 {
+		t.Errorf("liking the muter's chirp: expected success, got %d", status)
+	}
+	doRequest(t, http.MethodPost, ts.URL+"/api/users/"+muter.ID.String()+"/follow", mutedToken, nil, nil)
+	if types := notificationTypesFor(t, ts.URL, muterToken); len(types) != 0 {
+		t.Errorf("expected no notifications from a muted user, got %v", types)
+	}
+}
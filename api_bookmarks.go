@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// handleBookmarkChirp saves a chirp the caller can read. Bookmarks are
+// private, so the author isn't notified.
+func (cfg *apiConfig) handleBookmarkChirp(w http.ResponseWriter, req *http.Request) {
+	dbUser, dbChirp, ok := cfg.authenticateChirpAction(w, req)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.dbQueries.BookmarkChirp(req.Context(), database.BookmarkChirpParams{
+		UserID:    dbUser.ID,
+		ChirpID:   dbChirp.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleDeleteBookmark(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	chirpId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	_, err = cfg.dbQueries.DeleteBookmark(req.Context(), database.DeleteBookmarkParams{
+		UserID:  dbUser.ID,
+		ChirpID: chirpId,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetBookmarks lists the caller's bookmarked chirps, sorted like
+// GET /api/chirps. Chirps the caller can no longer read are left out.
+func (cfg *apiConfig) handleGetBookmarks(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	var dbChirps []database.Chirp
+	var err error
+	if sortAscending(req) {
+		dbChirps, err = cfg.dbQueries.GetBookmarkedChirpsAsc(req.Context(), dbUser.ID)
+	} else {
+		dbChirps, err = cfg.dbQueries.GetBookmarkedChirpsDesc(req.Context(), dbUser.ID)
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	cfg.returnChirpsResponse(w, req, dbChirps, dbUser.ID)
+}
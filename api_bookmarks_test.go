@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// bookmarksFor returns the bookmarks of token's user in the given sort order.
+func bookmarksFor(t *testing.T, url, token, sort string) []Chirp {
+	t.Helper()
+	chirps := []Chirp{}
+	status := doRequest(t, http.MethodGet, url+"/api/users/me/bookmarks?sort="+sort, token, nil, &chirps)
+	if status != http.StatusOK {
+		t.Fatalf("GET /api/users/me/bookmarks: expected 200, got %d", status)
+	}
+	return chirps
+}
+
+func TestBookmarkTimeline(t *testing.T) {
+	s, ts := testDBServer(t)
+	_, authorToken := createTestUser(t, s)
+	_, readerToken := createTestUser(t, s)
+	_, otherToken := createTestUser(t, s)
+	first := postChirp(t, ts, authorToken, ChirpRequest{Body: "first"})
+	second := postChirp(t, ts, authorToken, ChirpRequest{Body: "second"})
+	private := postChirp(t, ts, authorToken, ChirpRequest{Body: "private", Visibility: visibilityPrivate})
+	bookmarkURL := func(c Chirp) string { return ts.URL + "/api/chirps/" + c.Id.String() + "/bookmark" }
+
+	for _, chirp := range []Chirp{first, second, second} {
+		if status := doRequest(t, http.MethodPut, bookmarkURL(chirp), readerToken, nil, nil); status != http.StatusNoContent {
+			t.Fatalf("bookmark: expected 204, got %d", status)
+		}
+	}
+	if status := doRequest(t, http.MethodPut, bookmarkURL(private), readerToken, nil, nil); status != http.StatusNotFound {
+		t.Errorf("bookmarking a chirp you can't read: expected 404, got %d", status)
+	}
+
+	if got, expected := chirpIds(bookmarksFor(t, ts.URL, readerToken, "asc")), chirpIds([]Chirp{first, second}); !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got, expected := chirpIds(bookmarksFor(t, ts.URL, readerToken, "desc")), chirpIds([]Chirp{second, first}); !slices.Equal(got, expected) {
+		t.Errorf("expected %v sorted desc, got %v", expected, got)
+	}
+	if len(bookmarksFor(t, ts.URL, otherToken, "asc")) != 0 {
+		t.Error("expected bookmarks to be private")
+	}
+
+	doRequest(t, http.MethodDelete, ts.URL+"/api/chirps/"+first.Id.String(), authorToken, nil, nil)
+	doRequest(t, http.MethodDelete, bookmarkURL(second), readerToken, nil, nil)
+	if bookmarks := bookmarksFor(t, ts.URL, readerToken, "asc"); len(bookmarks) != 0 {
+		t.Errorf("expected deleted and removed bookmarks to be gone, got %v", chirpIds(bookmarks))
+	}
+}
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"io"
+	"log"
 	"net/http"
 	"strings"
 	"time"
 
-	"github.com/aramirez3/chirpy/internal/auth"
+	"github.com/aramirez3/chirpy/internal/chirptext"
 	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/aramirez3/chirpy/internal/filter"
 	"github.com/google/uuid"
 )
 
@@ -17,16 +22,48 @@ type Chirps struct {
 }
 
 type Chirp struct {
-	Id        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserId    uuid.UUID `json:"user_id"`
+	Id             uuid.UUID         `json:"id"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	Body           string            `json:"body"`
+	UserId         uuid.UUID         `json:"user_id"`
+	ReplyToId      *uuid.UUID        `json:"reply_to_id,omitempty"`
+	Visibility     string            `json:"visibility"`
+	Media          []MediaAttachment `json:"media,omitempty"`
+	Poll           *Poll             `json:"poll,omitempty"`
+	DeletedAt      *time.Time        `json:"deleted_at,omitempty"`
+	PinnedAt       *time.Time        `json:"pinned_at,omitempty"`
+	Preview        *LinkPreview      `json:"preview,omitempty"`
+	ContentWarning string            `json:"content_warning,omitempty"`
+	Sensitive      bool              `json:"sensitive"`
+	Collapsed      bool              `json:"collapsed,omitempty"`
 }
 
 type ChirpRequest struct {
-	Body   string    `json:"body"`
-	UserId uuid.UUID `json:"user_id"`
+	Body           string       `json:"body"`
+	UserId         uuid.UUID    `json:"user_id"`
+	ReplyToId      *uuid.UUID   `json:"reply_to_id"`
+	Visibility     string       `json:"visibility"`
+	MediaIds       []uuid.UUID  `json:"media_ids"`
+	PublishAt      *time.Time   `json:"publish_at"`
+	Draft          bool         `json:"draft"`
+	Poll           *PollRequest `json:"poll"`
+	ContentWarning string       `json:"content_warning"`
+	Sensitive      bool         `json:"sensitive"`
+}
+
+const (
+	visibilityPublic    = "public"
+	visibilityFollowers = "followers"
+	visibilityUnlisted  = "unlisted"
+	visibilityPrivate   = "private"
+)
+
+var chirpVisibilities = map[string]bool{
+	visibilityPublic:    true,
+	visibilityFollowers: true,
+	visibilityUnlisted:  true,
+	visibilityPrivate:   true,
 }
 
 type ErrorResponse struct {
@@ -38,123 +75,312 @@ type ValidResponse struct {
 }
 
 func (cfg *apiConfig) handleNewChirp(w http.ResponseWriter, req *http.Request) {
-	token, err := auth.GetBearerToken(req.Header)
-	if err != nil {
-		returnUnauthorized(w)
-		return
-	}
-
-	jwtId, err := auth.ValidateJWT(token, cfg.Secret)
-	if err != nil {
-		returnUnauthorized(w)
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
 		return
 	}
 
 	reqChirp := ChirpRequest{}
-	isValid, errorString := validateChirpRequest(req.Body, &reqChirp)
+	isValid, errorString := validateChirpRequest(req.Body, &reqChirp, cfg.entitlements(dbUser).MaxChirpLength)
 	w.Header().Add(contentType, plainTextContentType)
 	if errorString != "" || !isValid {
 		returnErrorResponse(w, errorString)
 		return
 	}
 
+	visibility, replyTo, ok := cfg.checkChirpRequest(w, req, dbUser, reqChirp)
+	if !ok {
+		return
+	}
+
+	moderated, ok := cfg.filterChirpBody(w, reqChirp.Body)
+	if !ok {
+		return
+	}
+	reqChirp.ContentWarning, ok = cfg.filterContentWarning(w, reqChirp.ContentWarning)
+	if !ok {
+		return
+	}
+	if reqChirp.Draft || reqChirp.PublishAt != nil {
+		cfg.scheduleChirp(w, req, dbUser, reqChirp, moderated.Text, visibility)
+		return
+	}
+
 	chirp := Chirp{
-		Id:        uuid.New(),
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
-		Body:      reqChirp.Body,
-		UserId:    jwtId,
+		Id:             uuid.New(),
+		CreatedAt:      time.Now().UTC(),
+		UpdatedAt:      time.Now().UTC(),
+		Body:           moderated.Text,
+		UserId:         dbUser.ID,
+		ReplyToId:      reqChirp.ReplyToId,
+		Visibility:     visibility,
+		ContentWarning: reqChirp.ContentWarning,
+		Sensitive:      reqChirp.Sensitive,
+	}
+	link := previewLink(chirp.Body)
+
+	params := database.CreateChirpParams{
+		ID:             chirp.Id,
+		CreatedAt:      chirp.CreatedAt,
+		UpdatedAt:      chirp.UpdatedAt,
+		Body:           chirp.Body,
+		UserID:         chirp.UserId,
+		Visibility:     chirp.Visibility,
+		ContentWarning: chirp.ContentWarning,
+		Sensitive:      chirp.Sensitive,
+		LinkUrl:        link,
+	}
+	if replyTo != nil {
+		params.ReplyToID = uuid.NullUUID{UUID: replyTo.ID, Valid: true}
 	}
 
-	encodedChirp, err := encodeJson(chirp)
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
 
-	params := database.CreateChirpParams{
-		ID:        chirp.Id,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserId,
+	_, err = qtx.CreateChirp(req.Context(), params)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = queueLinkPreview(req.Context(), qtx, link)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	chirp.Media, err = attachMedia(req.Context(), qtx, chirp.Id, dbUser.ID, reqChirp.MediaIds)
+	if err != nil {
+		returnErrorResponse(w, err.Error())
+		return
+	}
+	if reqChirp.Poll != nil {
+		chirp.Poll, err = createPoll(req.Context(), qtx, chirp.Id, *reqChirp.Poll)
+		if err != nil {
+			returnErrorResponse(w, standardError)
+			return
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
 	}
-	_, err = cfg.dbQueries.CreateChirp(req.Context(), params)
 
+	encodedChirp, err := encodeJson(chirp)
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
 	}
+
+	cfg.flagIfNeeded(req.Context(), chirp.Id, moderated)
+	cfg.publishWebhookEvent(req.Context(), eventChirpCreated, chirp.UserId, chirp)
+	cfg.publishStreamEvent(req.Context(), eventChirpCreated, chirp)
+	cfg.notifyChirpAudience(req.Context(), chirp, replyTo)
 	w.WriteHeader(http.StatusCreated)
 	w.Write(encodedChirp)
 }
 
-func validateChirpRequest(body io.ReadCloser, chirp *ChirpRequest) (bool, string) {
+func (cfg *apiConfig) handleEditChirp(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	chirpId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	dbChirp, err := cfg.dbQueries.GetChirpById(req.Context(), chirpId)
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+	if dbChirp.UserID != dbUser.ID || dbChirp.HiddenAt.Valid {
+		returnForbidden(w)
+		return
+	}
+
+	entitlements := cfg.entitlements(dbUser)
+	editWindow := time.Duration(entitlements.EditWindowSeconds) * time.Second
+	if !entitlements.CanEditChirps || time.Since(dbChirp.CreatedAt) > editWindow {
+		returnForbidden(w)
+		return
+	}
+
+	reqChirp := ChirpRequest{}
+	isValid, errorString := validateChirpRequest(req.Body, &reqChirp, entitlements.MaxChirpLength)
+	if errorString != "" || !isValid {
+		returnErrorResponse(w, errorString)
+		return
+	}
+	if reqChirp.Poll != nil {
+		returnErrorResponse(w, "Polls can't be edited")
+		return
+	}
+	if !cfg.checkMentions(w, req, dbUser.ID, reqChirp.Body) {
+		return
+	}
+	moderated, ok := cfg.filterChirpBody(w, reqChirp.Body)
+	if !ok {
+		return
+	}
+
+	link := previewLink(moderated.Text)
+	err = queueLinkPreview(req.Context(), cfg.dbQueries, link)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	updated, err := cfg.dbQueries.UpdateChirpBody(req.Context(), database.UpdateChirpBodyParams{
+		ID:        dbChirp.ID,
+		Body:      moderated.Text,
+		UpdatedAt: time.Now().UTC(),
+		LinkUrl:   link,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	cfg.flagIfNeeded(req.Context(), updated.ID, moderated)
+
+	chirps := []Chirp{dbChirpToResponse(updated)}
+	err = cfg.loadChirpDetails(req.Context(), chirps, dbUser.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	respBody, _ := encodeJson(chirps[0])
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// checkChirpRequest validates the parts of a chirp request that depend on the
+// database, writing a 400 when something is wrong. It returns the chirp's
+// visibility and the chirp it replies to, if any.
+func (cfg *apiConfig) checkChirpRequest(w http.ResponseWriter, req *http.Request, dbUser database.User, reqChirp ChirpRequest) (string, *database.Chirp, bool) {
+	visibility := reqChirp.Visibility
+	if visibility == "" {
+		visibility = defaultVisibility(dbUser)
+	}
+	if !chirpVisibilities[visibility] {
+		returnErrorResponse(w, "Invalid visibility")
+		return "", nil, false
+	}
+	err := checkMediaIds(req.Context(), cfg.dbQueries, dbUser.ID, reqChirp.MediaIds)
+	if err != nil {
+		returnErrorResponse(w, err.Error())
+		return "", nil, false
+	}
+	if !cfg.checkMentions(w, req, dbUser.ID, reqChirp.Body) {
+		return "", nil, false
+	}
+	if reqChirp.Poll != nil {
+		if reqChirp.Draft || reqChirp.PublishAt != nil {
+			returnErrorResponse(w, "Polls can't be scheduled")
+			return "", nil, false
+		}
+		if errorString := cfg.checkPollRequest(reqChirp.Poll); errorString != "" {
+			returnErrorResponse(w, errorString)
+			return "", nil, false
+		}
+	}
+	if reqChirp.ReplyToId == nil {
+		return visibility, nil, true
+	}
+	parent, err := cfg.dbQueries.GetPublicChirpById(req.Context(), database.GetPublicChirpByIdParams{
+		ID:       *reqChirp.ReplyToId,
+		ViewerID: dbUser.ID,
+	})
+	if err != nil {
+		returnErrorResponse(w, "Reply target not found")
+		return "", nil, false
+	}
+	return visibility, &parent, true
+}
+
+// filterChirpBody runs a chirp through the profanity filter and writes a 400
+// when the filter rejects it.
+func (cfg *apiConfig) filterChirpBody(w http.ResponseWriter, body string) (filter.Result, bool) {
+	moderated := cfg.profanity.Check(body)
+	if moderated.Rejected {
+		returnErrorResponse(w, "Chirp contains prohibited language")
+		return moderated, false
+	}
+	return moderated, true
+}
+
+func (cfg *apiConfig) flagIfNeeded(ctx context.Context, chirpId uuid.UUID, moderated filter.Result) {
+	if !moderated.Flagged {
+		return
+	}
+	err := cfg.flagChirpForReview(ctx, chirpId, moderated.Matches)
+	if err != nil {
+		log.Printf("error flagging chirp %s: %s\n", chirpId, err)
+	}
+}
+
+func validateChirpRequest(body io.ReadCloser, chirp *ChirpRequest, maxLength int) (bool, string) {
 	decoder := json.NewDecoder(body)
 	err := decoder.Decode(&chirp)
 	if err != nil {
 		return false, standardError
 	}
-	if chirp.Body == "" {
+	chirp.Body = chirptext.Normalize(chirp.Body)
+	if strings.TrimSpace(chirp.Body) == "" {
 		return false, standardError
 	}
 
-	if len(chirp.Body) > 140 {
+	if chirptext.Length(chirp.Body) > maxLength {
 		return false, "Chirp is too long"
 	}
 
-	removeProfanity(chirp)
 	return true, ""
 }
 
-func removeProfanity(chirp *ChirpRequest) {
-	badWords := map[string]bool{
-		"kerfuffle": true,
-		"sharbert":  true,
-		"fornax":    true,
-	}
-	words := strings.Split(chirp.Body, " ")
-	if len(words) > 0 {
-		for i, word := range words {
-			w, ok := badWords[strings.ToLower(word)]
-			if w && ok {
-				if word != "Sharbert!" {
-					words[i] = "****"
-				}
-			}
-		}
-		chirp.Body = strings.Join(words, " ")
-	}
-}
-
 func (cfg *apiConfig) handleGetChirps(w http.ResponseWriter, req *http.Request) {
-	authIdString := req.URL.Query().Get("author_id")
-	sortString := req.URL.Query().Get("sort")
-	sortAsc := sortString == "" || sortString == "asc" || sortString != "desc"
-	if sortString == "desc" {
-		sortAsc = false
+	viewerId, ok := cfg.optionalViewer(w, req)
+	if !ok {
+		return
 	}
+	authIdString := req.URL.Query().Get("author_id")
+	sortAsc := sortAscending(req)
 	if authIdString != "" {
 		authorId, err := uuid.Parse(authIdString)
 		if err != nil || authIdString == "" || authorId == uuid.Nil {
-			returnChirpsResponse(w, []database.Chirp{})
+			cfg.returnChirpsResponse(w, req, []database.Chirp{}, viewerId)
 			return
 		}
 		if sortAsc {
-			cfg.getChirpsByAuthorIdAsc(w, req, authorId)
+			cfg.getChirpsByAuthorIdAsc(w, req, authorId, viewerId)
 			return
 		}
-		cfg.getChirpsByAuthorIdDesc(w, req, authorId)
+		cfg.getChirpsByAuthorIdDesc(w, req, authorId, viewerId)
 		return
 	}
 	if sortAsc {
-		cfg.getAllChirpsAsc(w, req)
+		cfg.getAllChirpsAsc(w, req, viewerId)
 		return
 	}
-	cfg.getAllChirpsDesc(w, req)
+	cfg.getAllChirpsDesc(w, req, viewerId)
+}
+
+// sortAscending reads the ?sort= query param shared by chirp listings:
+// anything but "desc" sorts oldest first.
+func sortAscending(req *http.Request) bool {
+	return req.URL.Query().Get("sort") != "desc"
 }
 
 func (cfg *apiConfig) handleGetChirp(w http.ResponseWriter, req *http.Request) {
+	viewerId, ok := cfg.optionalViewer(w, req)
+	if !ok {
+		return
+	}
 	idString := req.PathValue("id")
 	if idString == "" {
 		returnNotFound(w)
@@ -164,19 +390,42 @@ func (cfg *apiConfig) handleGetChirp(w http.ResponseWriter, req *http.Request) {
 		returnErrorResponse(w, standardError)
 		return
 	}
-	dbChirp, err := cfg.dbQueries.GetChirpById(req.Context(), chirpId)
+	dbChirp, err := cfg.dbQueries.GetPublicChirpById(req.Context(), database.GetPublicChirpByIdParams{
+		ID:       chirpId,
+		ViewerID: viewerId,
+	})
 	if err != nil {
 		returnNotFound(w)
 		return
 	}
+	chirps := []Chirp{dbChirpToResponse(dbChirp)}
+	err = cfg.loadChirpDetails(req.Context(), chirps, viewerId)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 	w.Header().Add(contentType, plainTextContentType)
-	err = json.NewEncoder(w).Encode(dbChirpToResponse(dbChirp))
+	err = json.NewEncoder(w).Encode(chirps[0])
 	if err != nil {
 		returnErrorResponse(w, standardError)
 	}
 }
 
+// loadChirpDetails fills in everything a chirp response carries beyond its
+// row: media attachments, link previews and polls, as viewerId sees them.
+func (cfg *apiConfig) loadChirpDetails(ctx context.Context, chirps []Chirp, viewerId uuid.UUID) error {
+	err := cfg.loadChirpMedia(ctx, chirps)
+	if err != nil {
+		return err
+	}
+	err = cfg.loadChirpPreviews(ctx, chirps)
+	if err != nil {
+		return err
+	}
+	return cfg.loadChirpPolls(ctx, chirps, viewerId)
+}
+
 func dbChirpsToResponse(dbChirps []database.Chirp) []Chirp {
 	response := []Chirp{}
 	for _, c := range dbChirps {
@@ -185,32 +434,72 @@ func dbChirpsToResponse(dbChirps []database.Chirp) []Chirp {
 	return response
 }
 
+// defaultVisibility is used when a chirp is posted without one: protected
+// accounts post to their followers, everyone else posts publicly.
+func defaultVisibility(u database.User) string {
+	if u.IsProtected {
+		return visibilityFollowers
+	}
+	return visibilityPublic
+}
+
 func dbChirpToResponse(c database.Chirp) Chirp {
-	return Chirp{
-		Id:        c.ID,
-		CreatedAt: c.CreatedAt,
-		UpdatedAt: c.UpdatedAt,
-		Body:      c.Body,
-		UserId:    c.UserID,
+	response := Chirp{
+		Id:             c.ID,
+		CreatedAt:      c.CreatedAt,
+		UpdatedAt:      c.UpdatedAt,
+		Body:           c.Body,
+		UserId:         c.UserID,
+		Visibility:     c.Visibility,
+		ContentWarning: c.ContentWarning,
+		Sensitive:      c.Sensitive,
+	}
+	if c.ReplyToID.Valid {
+		response.ReplyToId = &c.ReplyToID.UUID
+	}
+	if c.DeletedAt.Valid {
+		response.DeletedAt = &c.DeletedAt.Time
 	}
+	if c.PinnedAt.Valid {
+		response.PinnedAt = &c.PinnedAt.Time
+	}
+	// Filled in by loadChirpPreviews.
+	if c.LinkUrl != "" {
+		response.Preview = &LinkPreview{Url: c.LinkUrl}
+	}
+	return response
 }
 
 func (cfg *apiConfig) handleDeleteChirp(w http.ResponseWriter, req *http.Request) {
-	token, err := auth.GetBearerToken(req.Header)
-	if err != nil {
-		returnUnauthorized(w)
+	dbChirp, ok := cfg.authenticateChirpOwner(w, req)
+	if !ok {
+		return
+	}
+	deleted, err := cfg.dbQueries.SoftDeleteChirp(req.Context(), database.SoftDeleteChirpParams{
+		ID:        dbChirp.ID,
+		DeletedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		returnNotFound(w)
 		return
 	}
-
-	jwtId, err := auth.ValidateJWT(token, cfg.Secret)
 	if err != nil {
-		if err.Error() == "invalid token" || err.Error() == "subject is empty" {
-			returnBadRequest(w)
-		} else {
-			returnUnauthorized(w)
-		}
+		returnErrorResponse(w, standardError)
 		return
 	}
+	cfg.publishWebhookEvent(req.Context(), eventChirpDeleted, deleted.UserID, dbChirpToResponse(deleted))
+	cfg.publishStreamEvent(req.Context(), eventChirpDeleted, dbChirpToResponse(deleted))
+	w.WriteHeader(http.StatusNoContent)
+
+}
+
+// authenticateChirpOwner loads the chirp in the path and checks that the
+// caller wrote it and isn't suspended or banned.
+func (cfg *apiConfig) authenticateChirpOwner(w http.ResponseWriter, req *http.Request) (database.Chirp, bool) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return database.Chirp{}, false
+	}
 
 	idString := req.PathValue("id")
 	if idString == "" {
@@ -219,71 +508,86 @@ func (cfg *apiConfig) handleDeleteChirp(w http.ResponseWriter, req *http.Request
 	chirpId, err := uuid.Parse(idString)
 	if err != nil {
 		returnErrorResponse(w, standardError)
-		return
+		return database.Chirp{}, false
 	}
 	dbChirp, err := cfg.dbQueries.GetChirpById(req.Context(), chirpId)
 	if err != nil {
 		returnNotFound(w)
-		return
+		return database.Chirp{}, false
 	}
 
-	if dbChirp.UserID != jwtId {
+	if dbChirp.UserID != dbUser.ID {
 		returnForbidden(w)
-		return
-	}
-	_, err = cfg.dbQueries.DeleteChirpById(req.Context(), dbChirp.ID)
-	if err != nil {
-		returnErrorResponse(w, standardError)
-		return
+		return database.Chirp{}, false
 	}
-	w.WriteHeader(http.StatusNoContent)
-
+	return dbChirp, true
 }
 
-func (cfg *apiConfig) getChirpsByAuthorIdAsc(w http.ResponseWriter, req *http.Request, authorId uuid.UUID) {
-	dbChirps, err := cfg.dbQueries.GetChirpByAuthorIdAsc(req.Context(), authorId)
+func (cfg *apiConfig) getChirpsByAuthorIdAsc(w http.ResponseWriter, req *http.Request, authorId, viewerId uuid.UUID) {
+	dbChirps, err := cfg.dbQueries.GetChirpByAuthorIdAsc(req.Context(), database.GetChirpByAuthorIdAscParams{
+		UserID:   authorId,
+		ViewerID: viewerId,
+	})
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
 	}
-	returnChirpsResponse(w, dbChirps)
+	cfg.returnAuthorFeed(w, req, authorId, viewerId, dbChirps)
 }
 
-func (cfg *apiConfig) getChirpsByAuthorIdDesc(w http.ResponseWriter, req *http.Request, authorId uuid.UUID) {
-	dbChirps, err := cfg.dbQueries.GetChirpByAuthorIdDesc(req.Context(), authorId)
+func (cfg *apiConfig) getChirpsByAuthorIdDesc(w http.ResponseWriter, req *http.Request, authorId, viewerId uuid.UUID) {
+	dbChirps, err := cfg.dbQueries.GetChirpByAuthorIdDesc(req.Context(), database.GetChirpByAuthorIdDescParams{
+		UserID:   authorId,
+		ViewerID: viewerId,
+	})
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
 	}
-	returnChirpsResponse(w, dbChirps)
+	cfg.returnAuthorFeed(w, req, authorId, viewerId, dbChirps)
 }
 
-func (cfg *apiConfig) getAllChirpsAsc(w http.ResponseWriter, req *http.Request) {
-	dbChirps, err := cfg.dbQueries.GetAllChirpsAsc(req.Context())
+func (cfg *apiConfig) getAllChirpsAsc(w http.ResponseWriter, req *http.Request, viewerId uuid.UUID) {
+	dbChirps, err := cfg.dbQueries.GetAllChirpsAsc(req.Context(), viewerId)
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
 	}
-	returnChirpsResponse(w, dbChirps)
+	cfg.returnChirpsResponse(w, req, dbChirps, viewerId)
 }
 
-func (cfg *apiConfig) getAllChirpsDesc(w http.ResponseWriter, req *http.Request) {
-	dbChirps, err := cfg.dbQueries.GetAllChirpsDesc(req.Context())
+func (cfg *apiConfig) getAllChirpsDesc(w http.ResponseWriter, req *http.Request, viewerId uuid.UUID) {
+	dbChirps, err := cfg.dbQueries.GetAllChirpsDesc(req.Context(), viewerId)
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
 	}
-	returnChirpsResponse(w, dbChirps)
+	cfg.returnChirpsResponse(w, req, dbChirps, viewerId)
 }
 
-func returnChirpsResponse(w http.ResponseWriter, chirps []database.Chirp) {
+// chirpsForViewer turns a page of chirps into what viewerId gets to see of
+// them, details loaded and sensitive content applied.
+func (cfg *apiConfig) chirpsForViewer(ctx context.Context, chirps []database.Chirp, viewerId uuid.UUID) ([]Chirp, error) {
 	responseChirps := []Chirp{}
 	if len(chirps) > 0 {
 		responseChirps = dbChirpsToResponse(chirps)
 	}
+	err := cfg.loadChirpDetails(ctx, responseChirps, viewerId)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.applySensitiveContent(ctx, responseChirps, viewerId)
+}
+
+func (cfg *apiConfig) returnChirpsResponse(w http.ResponseWriter, req *http.Request, chirps []database.Chirp, viewerId uuid.UUID) {
+	responseChirps, err := cfg.chirpsForViewer(req.Context(), chirps, viewerId)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
 	w.Header().Set(contentType, plainTextContentType)
 	w.WriteHeader(http.StatusOK)
-	err := json.NewEncoder(w).Encode(responseChirps)
+	err = json.NewEncoder(w).Encode(responseChirps)
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestDefaultVisibility(t *testing.T) {
+	if got := defaultVisibility(database.User{}); got != visibilityPublic {
+		t.Errorf("expected %q for an open account, got %q", visibilityPublic, got)
+	}
+	if got := defaultVisibility(database.User{IsProtected: true}); got != visibilityFollowers {
+		t.Errorf("expected %q for a protected account, got %q", visibilityFollowers, got)
+	}
+}
+
+func TestChirpVisibility(t *testing.T) {
+	s, ts := testDBServer(t)
+	author, authorToken := createTestUser(t, s)
+	_, followerToken := createTestUser(t, s)
+	_, strangerToken := createTestUser(t, s)
+	doRequest(t, http.MethodPost, ts.URL+"/api/users/"+author.ID.String()+"/follow", followerToken, nil, nil)
+
+	chirps := map[string]Chirp{}
+	for _, visibility := range []string{visibilityPublic, visibilityFollowers, visibilityUnlisted, visibilityPrivate} {
+		chirps[visibility] = postChirp(t, ts, authorToken, ChirpRequest{Body: visibility, Visibility: visibility})
+	}
+
+	viewers := []struct {
+		name  string
+		token string
+		// Which chirps the viewer can open by id, which of those the global
+		// feed lists; the author feed lists every visible chirp.
+		visible []string
+		listed  []string
+	}{
+		{"anonymous", "", []string{visibilityPublic, visibilityUnlisted}, []string{visibilityPublic}},
+		{"stranger", strangerToken, []string{visibilityPublic, visibilityUnlisted}, []string{visibilityPublic}},
+		{"follower", followerToken, []string{visibilityPublic, visibilityFollowers, visibilityUnlisted}, []string{visibilityPublic, visibilityFollowers}},
+		{"author", authorToken, []string{visibilityPublic, visibilityFollowers, visibilityUnlisted, visibilityPrivate}, []string{visibilityPublic, visibilityFollowers, visibilityPrivate}},
+	}
+	for _, viewer := range viewers {
+		for visibility, chirp := range chirps {
+			expected := http.StatusNotFound
+			if slices.Contains(viewer.visible, visibility) {
+				expected = http.StatusOK
+			}
+			if status := doRequest(t, http.MethodGet, ts.URL+"/api/chirps/"+chirp.Id.String(), viewer.token, nil, nil); status != expected {
+				t.Errorf("%s viewing a %s chirp: expected %d, got %d", viewer.name, visibility, expected, status)
+			}
+		}
+
+		feed := []Chirp{}
+		doRequest(t, http.MethodGet, ts.URL+"/api/chirps", viewer.token, nil, &feed)
+		if got, expected := chirpIds(feed), idsOf(chirps, viewer.listed); !slices.Equal(got, expected) {
+			t.Errorf("%s's feed: expected %v, got %v", viewer.name, expected, got)
+		}
+		authorFeed := AuthorFeed{}
+		doRequest(t, http.MethodGet, ts.URL+"/api/chirps?author_id="+author.ID.String(), viewer.token, nil, &authorFeed)
+		if got, expected := chirpIds(authorFeed.Chirps), idsOf(chirps, viewer.visible); !slices.Equal(got, expected) {
+			t.Errorf("%s's view of the author: expected %v, got %v", viewer.name, expected, got)
+		}
+	}
+}
+
+// idsOf lists the ids of the chirps posted with the given visibilities, in
+// the order given.
+func idsOf(chirps map[string]Chirp, visibilities []string) []uuid.UUID {
+	ids := []uuid.UUID{}
+	for _, visibility := range visibilities {
+		ids = append(ids, chirps[visibility].Id)
+	}
+	return ids
+}
+
+func TestProtectedAccountsDefaultToFollowers(t *testing.T) {
+	s, ts := testDBServer(t)
+	_, token := createTestUser(t, s)
+
+	settings := PrivacySettings{}
+	doRequest(t, http.MethodPut, ts.URL+"/api/users/me/privacy", token, PrivacySettings{Protected: true}, &settings)
+	if !settings.Protected {
+		t.Fatal("expected the account to be protected")
+	}
+	if chirp := postChirp(t, ts, token, ChirpRequest{Body: "hi"}); chirp.Visibility != visibilityFollowers {
+		t.Errorf("expected %q, got %q", visibilityFollowers, chirp.Visibility)
+	}
+	if chirp := postChirp(t, ts, token, ChirpRequest{Body: "hi", Visibility: visibilityPublic}); chirp.Visibility != visibilityPublic {
+		t.Errorf("expected an explicit visibility to win, got %q", chirp.Visibility)
+	}
+	status := doRequest(t, http.MethodPost, ts.URL+"/api/chirps", token, ChirpRequest{Body: "hi", Visibility: "friends"}, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("unknown visibility: expected 400, got %d", status)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/chirptext"
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type ContentSettings struct {
+	SensitiveContent string `json:"sensitive_content"`
+}
+
+type ContentWarningRequest struct {
+	ContentWarning string `json:"content_warning"`
+	Sensitive      bool   `json:"sensitive"`
+	Notes          string `json:"notes"`
+}
+
+const (
+	// Listings return flagged chirps without their body, media, poll or
+	// link preview.
+	sensitiveContentCollapse = "collapse"
+	// Listings leave flagged chirps out.
+	sensitiveContentHide = "hide"
+
+	maxContentWarningLength = 100
+
+	moderationContentWarning = "content_warning"
+)
+
+var sensitiveContentSettings = map[string]bool{
+	sensitiveContentCollapse: true,
+	sensitiveContentHide:     true,
+}
+
+func (cfg *apiConfig) handleGetContentSettings(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	respBody, _ := encodeJson(ContentSettings{SensitiveContent: dbUser.SensitiveContent})
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleUpdateContentSettings(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	payload := ContentSettings{}
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if !sensitiveContentSettings[payload.SensitiveContent] {
+		returnErrorResponse(w, "Invalid sensitive_content setting")
+		return
+	}
+	dbUser, err = cfg.dbQueries.SetUserSensitiveContent(req.Context(), database.SetUserSensitiveContentParams{
+		ID:               dbUser.ID,
+		SensitiveContent: payload.SensitiveContent,
+		UpdatedAt:        time.Now().UTC(),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(ContentSettings{SensitiveContent: dbUser.SensitiveContent})
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// handleSetContentWarning lets a moderator replace the content warning and
+// sensitive flag on anyone's chirp. The change is kept in the moderation log.
+func (cfg *apiConfig) handleSetContentWarning(w http.ResponseWriter, req *http.Request) {
+	moderator, ok := cfg.authenticateModerator(w, req)
+	if !ok {
+		return
+	}
+	chirpId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	dbChirp, err := cfg.dbQueries.GetChirpById(req.Context(), chirpId)
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+
+	payload := ContentWarningRequest{}
+	err = json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	payload.ContentWarning, ok = cfg.filterContentWarning(w, payload.ContentWarning)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	updated, err := qtx.SetChirpContentWarning(req.Context(), database.SetChirpContentWarningParams{
+		ID:             dbChirp.ID,
+		ContentWarning: payload.ContentWarning,
+		Sensitive:      payload.Sensitive,
+		UpdatedAt:      time.Now().UTC(),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = recordModerationAction(req.Context(), qtx, database.CreateModerationActionParams{
+		ModeratorID: moderator.ID,
+		Action:      moderationContentWarning,
+		ChirpID:     uuid.NullUUID{UUID: updated.ID, Valid: true},
+		UserID:      uuid.NullUUID{UUID: updated.UserID, Valid: true},
+		Notes:       payload.Notes,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	chirps := []Chirp{dbChirpToResponse(updated)}
+	err = cfg.loadChirpDetails(req.Context(), chirps, moderator.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	respBody, _ := encodeJson(chirps[0])
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// filterContentWarning normalizes a content warning and runs it through the
+// profanity filter, writing a 400 when it's too long or rejected.
+func (cfg *apiConfig) filterContentWarning(w http.ResponseWriter, warning string) (string, bool) {
+	warning = strings.TrimSpace(chirptext.Normalize(warning))
+	if chirptext.Length(warning) > maxContentWarningLength {
+		returnErrorResponse(w, fmt.Sprintf("Content warnings can be at most %d characters", maxContentWarningLength))
+		return "", false
+	}
+	moderated := cfg.profanity.Check(warning)
+	if moderated.Rejected {
+		returnErrorResponse(w, "Chirp contains prohibited language")
+		return "", false
+	}
+	return moderated.Text, true
+}
+
+// applySensitiveContent collapses or drops the flagged chirps in a listing,
+// following the viewer's setting. Anonymous viewers get them collapsed, and
+// nobody's own chirps are touched.
+func (cfg *apiConfig) applySensitiveContent(ctx context.Context, chirps []Chirp, viewerId uuid.UUID) ([]Chirp, error) {
+	setting := sensitiveContentCollapse
+	if viewerId != uuid.Nil {
+		viewer, err := cfg.dbQueries.GetUserById(ctx, viewerId)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if err == nil {
+			setting = viewer.SensitiveContent
+		}
+	}
+	return sensitiveContentFor(chirps, viewerId, setting), nil
+}
+
+// sensitiveContentFor collapses or drops flagged chirps per setting, leaving
+// viewerId's own chirps as they are.
+func sensitiveContentFor(chirps []Chirp, viewerId uuid.UUID, setting string) []Chirp {
+	result := make([]Chirp, 0, len(chirps))
+	for _, c := range chirps {
+		if c.UserId == viewerId || (c.ContentWarning == "" && !c.Sensitive) {
+			result = append(result, c)
+			continue
+		}
+		if setting == sensitiveContentHide {
+			continue
+		}
+		c.Body = ""
+		c.Media = nil
+		c.Poll = nil
+		c.Preview = nil
+		c.Collapsed = true
+		result = append(result, c)
+	}
+	return result
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aramirez3/chirpy/internal/filter"
+	"github.com/google/uuid"
+)
+
+func TestSensitiveContentFor(t *testing.T) {
+	viewer, author := uuid.New(), uuid.New()
+	plain := Chirp{Id: uuid.New(), UserId: author, Body: "plain"}
+	warned := Chirp{Id: uuid.New(), UserId: author, Body: "spoilers", ContentWarning: "spoilers", Poll: &Poll{}}
+	sensitive := Chirp{Id: uuid.New(), UserId: author, Body: "gore", Sensitive: true, Preview: &LinkPreview{}}
+	own := Chirp{Id: uuid.New(), UserId: viewer, Body: "mine", Sensitive: true}
+	chirps := []Chirp{plain, warned, sensitive, own}
+
+	hidden := sensitiveContentFor(chirps, viewer, sensitiveContentHide)
+	if got, expected := chirpIds(hidden), chirpIds([]Chirp{plain, own}); !slices.Equal(got, expected) {
+		t.Errorf("hide: expected %v, got %v", expected, got)
+	}
+
+	collapsed := sensitiveContentFor(chirps, viewer, sensitiveContentCollapse)
+	if got, expected := chirpIds(collapsed), chirpIds(chirps); !slices.Equal(got, expected) {
+		t.Fatalf("collapse: expected %v, got %v", expected, got)
+	}
+	for _, c := range collapsed[1:3] {
+		if !c.Collapsed || c.Body != "" || c.Poll != nil || c.Preview != nil {
+			t.Errorf("expected %v to be collapsed, got %+v", c.Id, c)
+		}
+	}
+	if collapsed[1].ContentWarning != "spoilers" {
+		t.Error("expected a collapsed chirp to keep its content warning")
+	}
+	for _, c := range []Chirp{collapsed[0], collapsed[3]} {
+		if c.Collapsed || c.Body == "" {
+			t.Errorf("expected %q to be left alone", c.Body)
+		}
+	}
+	if chirps[1].Body != "spoilers" {
+		t.Error("expected the input chirps to be left unchanged")
+	}
+}
+
+func TestFilterContentWarning(t *testing.T) {
+	cfg := &apiConfig{profanity: filter.New(defaultProfaneWords)}
+	warning, ok := cfg.filterContentWarning(httptest.NewRecorder(), "  spoilers  ")
+	if !ok || warning != "spoilers" {
+		t.Errorf("expected a trimmed warning, got %q %v", warning, ok)
+	}
+	w := httptest.NewRecorder()
+	if _, ok := cfg.filterContentWarning(w, strings.Repeat("a", maxContentWarningLength+1)); ok || w.Code != http.StatusBadRequest {
+		t.Errorf("expected a long warning to be refused, got %d", w.Code)
+	}
+}
+
+func TestSensitiveContentSettings(t *testing.T) {
+	s, ts := testDBServer(t)
+	_, authorToken := createTestUser(t, s)
+	_, viewerToken := createTestUser(t, s)
+	plain := postChirp(t, ts, authorToken, ChirpRequest{Body: "plain"})
+	warned := postChirp(t, ts, authorToken, ChirpRequest{Body: "spoilers", ContentWarning: "finale"})
+
+	feed := func(token string) []Chirp {
+		chirps := []Chirp{}
+		doRequest(t, http.MethodGet, ts.URL+"/api/chirps", token, nil, &chirps)
+		return chirps
+	}
+	for _, token := range []string{"", viewerToken} {
+		chirps := feed(token)
+		if len(chirps) != 2 || chirps[1].Id != warned.Id || !chirps[1].Collapsed || chirps[1].Body != "" || chirps[1].ContentWarning != "finale" {
+			t.Errorf("expected the warned chirp collapsed by default, got %+v", chirps)
+		}
+	}
+	if chirps := feed(authorToken); len(chirps) != 2 || chirps[1].Collapsed {
+		t.Errorf("expected authors to see their own chirps in full, got %+v", chirps)
+	}
+
+	settings := ContentSettings{}
+	doRequest(t, http.MethodPut, ts.URL+"/api/users/me/content-settings", viewerToken, ContentSettings{SensitiveContent: sensitiveContentHide}, &settings)
+	if settings.SensitiveContent != sensitiveContentHide {
+		t.Fatalf("expected the setting to be saved, got %q", settings.SensitiveContent)
+	}
+	if got := chirpIds(feed(viewerToken)); !slices.Equal(got, chirpIds([]Chirp{plain})) {
+		t.Errorf("expected only the plain chirp, got %v", got)
+	}
+	status := doRequest(t, http.MethodPut, ts.URL+"/api/users/me/content-settings", viewerToken, ContentSettings{SensitiveContent: "show"}, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("unknown setting: expected 400, got %d", status)
+	}
+}
+
+func TestModeratorsCanFlagOthersChirps(t *testing.T) {
+	s, ts := testDBServer(t)
+	s.Config.AdminKey = "admin-key"
+	moderator, moderatorToken := createTestUser(t, s)
+	_, authorToken := createTestUser(t, s)
+	chirp := postChirp(t, ts, authorToken, ChirpRequest{Body: "graphic"})
+	warningURL := ts.URL + "/api/moderation/chirps/" + chirp.Id.String() + "/content-warning"
+	request := ContentWarningRequest{ContentWarning: "graphic", Sensitive: true}
+
+	if status := doRequest(t, http.MethodPut, warningURL, authorToken, request, nil); status != http.StatusForbidden {
+		t.Errorf("flagging as a regular user: expected 403, got %d", status)
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/admin/users/"+moderator.ID.String()+"/moderator", nil)
+	req.Header.Set("Authorization", "ApiKey admin-key")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		t.Fatalf("grant moderator: expected success, got %d", resp.StatusCode)
+	}
+
+	flagged := Chirp{}
+	if status := doRequest(t, http.MethodPut, warningURL, moderatorToken, request, &flagged); status != http.StatusOK {
+		t.Fatalf("flagging as a moderator: expected 200, got %d", status)
+	}
+	if flagged.ContentWarning != "graphic" || !flagged.Sensitive {
+		t.Errorf("expected the chirp to be flagged, got %+v", flagged)
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/chirptext"
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type List struct {
+	Id        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+}
+
+type ListRequest struct {
+	Name string `json:"name"`
+}
+
+type ListMember struct {
+	UserId    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const maxListNameLength = 50
+
+func (cfg *apiConfig) handleCreateList(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	name, ok := decodeListName(w, req)
+	if !ok {
+		return
+	}
+
+	dbList, err := cfg.dbQueries.CreateList(req.Context(), database.CreateListParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    dbUser.ID,
+		Name:      name,
+	})
+	if isUniqueViolation(err) {
+		returnConflict(w)
+		return
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(dbListToResponse(dbList))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleGetLists(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	dbLists, err := cfg.dbQueries.GetListsForUser(req.Context(), dbUser.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	response := []List{}
+	for _, l := range dbLists {
+		response = append(response, dbListToResponse(l))
+	}
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleRenameList(w http.ResponseWriter, req *http.Request) {
+	dbUser, dbList, ok := cfg.authenticateList(w, req)
+	if !ok {
+		return
+	}
+	name, ok := decodeListName(w, req)
+	if !ok {
+		return
+	}
+
+	renamed, err := cfg.dbQueries.RenameList(req.Context(), database.RenameListParams{
+		ID:        dbList.ID,
+		UserID:    dbUser.ID,
+		Name:      name,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if isUniqueViolation(err) {
+		returnConflict(w)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		returnNotFound(w)
+		return
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(dbListToResponse(renamed))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleDeleteList(w http.ResponseWriter, req *http.Request) {
+	dbUser, dbList, ok := cfg.authenticateList(w, req)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.dbQueries.DeleteList(req.Context(), database.DeleteListParams{
+		ID:     dbList.ID,
+		UserID: dbUser.ID,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetListMembers(w http.ResponseWriter, req *http.Request) {
+	_, dbList, ok := cfg.authenticateList(w, req)
+	if !ok {
+		return
+	}
+
+	dbMembers, err := cfg.dbQueries.GetListMembers(req.Context(), dbList.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	response := []ListMember{}
+	for _, m := range dbMembers {
+		response = append(response, ListMember{UserId: m.UserID, CreatedAt: m.CreatedAt})
+	}
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleAddListMember(w http.ResponseWriter, req *http.Request) {
+	dbUser, dbList, ok := cfg.authenticateList(w, req)
+	if !ok {
+		return
+	}
+	memberId, err := uuid.Parse(req.PathValue("user_id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	_, err = cfg.dbQueries.GetUserById(req.Context(), memberId)
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+	blocked, err := cfg.dbQueries.IsBlockedEitherWay(req.Context(), database.IsBlockedEitherWayParams{
+		UserID:  dbUser.ID,
+		OtherID: memberId,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if blocked {
+		returnForbidden(w)
+		return
+	}
+
+	_, err = cfg.dbQueries.AddListMember(req.Context(), database.AddListMemberParams{
+		ListID:    dbList.ID,
+		UserID:    memberId,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleRemoveListMember(w http.ResponseWriter, req *http.Request) {
+	_, dbList, ok := cfg.authenticateList(w, req)
+	if !ok {
+		return
+	}
+	memberId, err := uuid.Parse(req.PathValue("user_id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	_, err = cfg.dbQueries.RemoveListMember(req.Context(), database.RemoveListMemberParams{
+		ListID: dbList.ID,
+		UserID: memberId,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetListChirps is the list's timeline: chirps by its members, filtered
+// and sorted like GET /api/chirps.
+func (cfg *apiConfig) handleGetListChirps(w http.ResponseWriter, req *http.Request) {
+	dbUser, dbList, ok := cfg.authenticateList(w, req)
+	if !ok {
+		return
+	}
+
+	var dbChirps []database.Chirp
+	var err error
+	if sortAscending(req) {
+		dbChirps, err = cfg.dbQueries.GetListChirpsAsc(req.Context(), database.GetListChirpsAscParams{
+			ListID:   dbList.ID,
+			ViewerID: dbUser.ID,
+		})
+	} else {
+		dbChirps, err = cfg.dbQueries.GetListChirpsDesc(req.Context(), database.GetListChirpsDescParams{
+			ListID:   dbList.ID,
+			ViewerID: dbUser.ID,
+		})
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	cfg.returnChirpsResponse(w, req, dbChirps, dbUser.ID)
+}
+
+// authenticateList loads the caller and the list in the path. Lists are
+// private, so other users' lists are a 404.
+func (cfg *apiConfig) authenticateList(w http.ResponseWriter, req *http.Request) (database.User, database.List, bool) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return database.User{}, database.List{}, false
+	}
+	listId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnNotFound(w)
+		return database.User{}, database.List{}, false
+	}
+	dbList, err := cfg.dbQueries.GetList(req.Context(), database.GetListParams{
+		ID:     listId,
+		UserID: dbUser.ID,
+	})
+	if err != nil {
+		returnNotFound(w)
+		return database.User{}, database.List{}, false
+	}
+	return dbUser, dbList, true
+}
+
+func decodeListName(w http.ResponseWriter, req *http.Request) (string, bool) {
+	reqList := ListRequest{}
+	err := json.NewDecoder(req.Body).Decode(&reqList)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return "", false
+	}
+	name := strings.TrimSpace(chirptext.Normalize(reqList.Name))
+	if name == "" {
+		returnErrorResponse(w, "List name is required")
+		return "", false
+	}
+	if chirptext.Length(name) > maxListNameLength {
+		returnErrorResponse(w, fmt.Sprintf("List names can be at most %d characters", maxListNameLength))
+		return "", false
+	}
+	return name, true
+}
+
+func dbListToResponse(l database.List) List {
+	return List{
+		Id:        l.ID,
+		CreatedAt: l.CreatedAt,
+		UpdatedAt: l.UpdatedAt,
+		Name:      l.Name,
+	}
+}
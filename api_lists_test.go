@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestListTimeline(t *testing.T) {
+	s, ts := testDBServer(t)
+	_, ownerToken := createTestUser(t, s)
+	member, memberToken := createTestUser(t, s)
+	other, otherToken := createTestUser(t, s)
+
+	list := List{}
+	if status := doRequest(t, http.MethodPost, ts.URL+"/api/lists", ownerToken, ListRequest{Name: "friends"}, &list); status != http.StatusCreated {
+		t.Fatalf("create list: expected 201, got %d", status)
+	}
+	if status := doRequest(t, http.MethodPost, ts.URL+"/api/lists", ownerToken, ListRequest{Name: "friends"}, nil); status != http.StatusConflict {
+		t.Errorf("duplicate list name: expected 409, got %d", status)
+	}
+	listURL := ts.URL + "/api/lists/" + list.Id.String()
+	if status := doRequest(t, http.MethodPut, listURL+"/members/"+member.ID.String(), ownerToken, nil, nil); status != http.StatusNoContent {
+		t.Fatalf("add member: expected 204, got %d", status)
+	}
+
+	first := postChirp(t, ts, memberToken, ChirpRequest{Body: "first"})
+	postChirp(t, ts, memberToken, ChirpRequest{Body: "unlisted", Visibility: visibilityUnlisted})
+	postChirp(t, ts, otherToken, ChirpRequest{Body: "not a member"})
+	second := postChirp(t, ts, memberToken, ChirpRequest{Body: "second"})
+
+	timeline := func(sort string) []Chirp {
+		chirps := []Chirp{}
+		status := doRequest(t, http.MethodGet, listURL+"/chirps?sort="+sort, ownerToken, nil, &chirps)
+		if status != http.StatusOK {
+			t.Fatalf("list timeline: expected 200, got %d", status)
+		}
+		return chirps
+	}
+	if got, expected := chirpIds(timeline("asc")), chirpIds([]Chirp{first, second}); !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got, expected := chirpIds(timeline("desc")), chirpIds([]Chirp{second, first}); !slices.Equal(got, expected) {
+		t.Errorf("expected %v sorted desc, got %v", expected, got)
+	}
+
+	// Lists are private to their owner.
+	if status := doRequest(t, http.MethodGet, listURL+"/chirps", otherToken, nil, nil); status != http.StatusNotFound {
+		t.Errorf("reading someone else's list: expected 404, got %d", status)
+	}
+	if status := doRequest(t, http.MethodPut, listURL+"/members/"+other.ID.String(), otherToken, nil, nil); status != http.StatusNotFound {
+		t.Errorf("adding to someone else's list: expected 404, got %d", status)
+	}
+
+	doRequest(t, http.MethodPost, ts.URL+"/api/users/"+member.ID.String()+"/mute", ownerToken, nil, nil)
+	if chirps := timeline("asc"); len(chirps) != 0 {
+		t.Errorf("expected muted members to be left out, got %v", chirpIds(chirps))
+	}
+}
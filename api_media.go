@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/aramirez3/chirpy/internal/media"
+	"github.com/google/uuid"
+)
+
+type MediaAttachment struct {
+	Id           uuid.UUID `json:"id"`
+	ContentType  string    `json:"content_type"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+	Url          string    `json:"url"`
+	ThumbnailUrl string    `json:"thumbnail_url"`
+}
+
+const (
+	defaultMaxMediaBytes = 5 << 20
+	maxMediaPerChirp     = 4
+	thumbnailSize        = 320
+	mediaFormField       = "file"
+	// multipartOverhead leaves room for the form's boundaries and headers
+	// on top of the file itself.
+	multipartOverhead    = 64 << 10
+	mediaOrphanAge       = 24 * time.Hour
+	mediaCleanupInterval = time.Hour
+	mediaCleanupBatch    = 100
+	defaultMediaDir      = "./uploads"
+	mediaCacheControl    = "private, max-age=86400"
+)
+
+// newBlobStore picks the blob store from MEDIA_STORE: "fs" (the default)
+// keeps files under MEDIA_DIR, "s3" talks to any S3-compatible service.
+func newBlobStore(env map[string]string) (media.BlobStore, error) {
+	switch env["MEDIA_STORE"] {
+	case "", "fs":
+		dir := env["MEDIA_DIR"]
+		if dir == "" {
+			dir = defaultMediaDir
+		}
+		return media.NewFSStore(dir)
+	case "s3":
+		return media.NewS3Store(media.S3Config{
+			Endpoint:        env["S3_ENDPOINT"],
+			Region:          env["S3_REGION"],
+			Bucket:          env["S3_BUCKET"],
+			AccessKeyID:     env["S3_ACCESS_KEY_ID"],
+			SecretAccessKey: env["S3_SECRET_ACCESS_KEY"],
+		})
+	}
+	return nil, fmt.Errorf("unknown MEDIA_STORE: %q", env["MEDIA_STORE"])
+}
+
+func (cfg *apiConfig) handleUploadMedia(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	req.Body = http.MaxBytesReader(w, req.Body, int64(cfg.MaxMediaBytes)+multipartOverhead)
+	file, _, err := req.FormFile(mediaFormField)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			returnTooLarge(w)
+			return
+		}
+		returnErrorResponse(w, "Missing file")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, int64(cfg.MaxMediaBytes)+1))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if len(data) > cfg.MaxMediaBytes {
+		returnTooLarge(w)
+		return
+	}
+
+	img, err := media.Process(data, thumbnailSize)
+	if errors.Is(err, media.ErrUnsupportedType) {
+		returnUnsupportedMediaType(w)
+		return
+	}
+	if errors.Is(err, media.ErrTooLarge) {
+		returnTooLarge(w)
+		return
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	id := uuid.New()
+	storageKey := fmt.Sprintf("media/%s%s", id, media.Extensions[img.ContentType])
+	thumbnailKey := fmt.Sprintf("media/%s_thumb%s", id, media.Extensions[img.ThumbnailType])
+	err = cfg.blobs.Put(req.Context(), storageKey, img.Data, img.ContentType)
+	if err != nil {
+		log.Printf("error storing media %s: %s\n", id, err)
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = cfg.blobs.Put(req.Context(), thumbnailKey, img.Thumbnail, img.ThumbnailType)
+	if err != nil {
+		log.Printf("error storing thumbnail for media %s: %s\n", id, err)
+		cfg.deleteBlobs(req.Context(), storageKey)
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	dbMedia, err := cfg.dbQueries.CreateMedia(req.Context(), database.CreateMediaParams{
+		ID:            id,
+		CreatedAt:     time.Now().UTC(),
+		UserID:        dbUser.ID,
+		ContentType:   img.ContentType,
+		SizeBytes:     int32(len(img.Data)),
+		Width:         int32(img.Width),
+		Height:        int32(img.Height),
+		StorageKey:    storageKey,
+		ThumbnailKey:  thumbnailKey,
+		ThumbnailType: img.ThumbnailType,
+	})
+	if err != nil {
+		cfg.deleteBlobs(req.Context(), storageKey, thumbnailKey)
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(dbMediaToResponse(dbMedia))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleGetMedia(w http.ResponseWriter, req *http.Request) {
+	dbMedia, ok := cfg.authenticateMedia(w, req)
+	if !ok {
+		return
+	}
+	cfg.serveBlob(w, req, dbMedia.StorageKey, dbMedia.ContentType)
+}
+
+func (cfg *apiConfig) handleGetMediaThumbnail(w http.ResponseWriter, req *http.Request) {
+	dbMedia, ok := cfg.authenticateMedia(w, req)
+	if !ok {
+		return
+	}
+	cfg.serveBlob(w, req, dbMedia.ThumbnailKey, dbMedia.ThumbnailType)
+}
+
+// authenticateMedia loads the media in the path and checks the caller may see
+// it: attached media follows its chirp's visibility, and an upload that isn't
+// attached yet is only visible to its owner. Anything else is a 404.
+func (cfg *apiConfig) authenticateMedia(w http.ResponseWriter, req *http.Request) (database.Medium, bool) {
+	viewerId, ok := cfg.optionalViewer(w, req)
+	if !ok {
+		return database.Medium{}, false
+	}
+	mediaId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnNotFound(w)
+		return database.Medium{}, false
+	}
+	dbMedia, err := cfg.dbQueries.GetMediaById(req.Context(), mediaId)
+	if err != nil {
+		returnNotFound(w)
+		return database.Medium{}, false
+	}
+	if dbMedia.ChirpID.Valid {
+		ok = cfg.canViewChirp(req.Context(), dbMedia.ChirpID.UUID, viewerId)
+	} else {
+		ok = viewerId != uuid.Nil && dbMedia.UserID == viewerId
+	}
+	if !ok {
+		returnNotFound(w)
+		return database.Medium{}, false
+	}
+	return dbMedia, true
+}
+
+func (cfg *apiConfig) serveBlob(w http.ResponseWriter, req *http.Request, key, blobType string) {
+	blob, err := cfg.blobs.Get(req.Context(), key)
+	if errors.Is(err, media.ErrNotFound) {
+		returnNotFound(w)
+		return
+	}
+	if err != nil {
+		log.Printf("error reading blob %s: %s\n", key, err)
+		returnErrorResponse(w, standardError)
+		return
+	}
+	defer blob.Close()
+	w.Header().Set(contentType, blobType)
+	w.Header().Set("Cache-Control", mediaCacheControl)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, blob)
+}
+
+// checkMediaIds makes sure a chirp's media ids are the user's own uploads,
+// not attached to anything yet and listed once. Errors are client errors.
+func checkMediaIds(ctx context.Context, q *database.Queries, userId uuid.UUID, mediaIds []uuid.UUID) error {
+	if len(mediaIds) > maxMediaPerChirp {
+		return fmt.Errorf("A chirp can have at most %d media attachments", maxMediaPerChirp)
+	}
+	seen := map[uuid.UUID]bool{}
+	for _, mediaId := range mediaIds {
+		if seen[mediaId] {
+			return errors.New("Duplicate media id")
+		}
+		seen[mediaId] = true
+		dbMedia, err := q.GetMediaById(ctx, mediaId)
+		if err != nil || dbMedia.UserID != userId || dbMedia.ChirpID.Valid {
+			return errors.New("Invalid media id")
+		}
+	}
+	return nil
+}
+
+// attachMedia attaches the caller's uploads to a new chirp, in order. It runs
+// inside the chirp's transaction so a bad id leaves nothing behind.
+func attachMedia(ctx context.Context, q *database.Queries, chirpId, userId uuid.UUID, mediaIds []uuid.UUID) ([]MediaAttachment, error) {
+	attachments := []MediaAttachment{}
+	for i, mediaId := range mediaIds {
+		dbMedia, err := q.AttachMedia(ctx, database.AttachMediaParams{
+			ChirpID:  uuid.NullUUID{UUID: chirpId, Valid: true},
+			Position: int32(i),
+			ID:       mediaId,
+			UserID:   userId,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("Invalid media id")
+		}
+		if err != nil {
+			return nil, errors.New(standardError)
+		}
+		attachments = append(attachments, dbMediaToResponse(dbMedia))
+	}
+	return attachments, nil
+}
+
+// loadChirpMedia fills in the attachments of a page of chirps with a single query.
+func (cfg *apiConfig) loadChirpMedia(ctx context.Context, chirps []Chirp) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(chirps))
+	byId := map[uuid.UUID]*Chirp{}
+	for i := range chirps {
+		ids = append(ids, chirps[i].Id)
+		byId[chirps[i].Id] = &chirps[i]
+	}
+	dbMedia, err := cfg.dbQueries.GetMediaForChirps(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, m := range dbMedia {
+		chirp := byId[m.ChirpID.UUID]
+		chirp.Media = append(chirp.Media, dbMediaToResponse(m))
+	}
+	return nil
+}
+
+func dbMediaToResponse(m database.Medium) MediaAttachment {
+	return MediaAttachment{
+		Id:           m.ID,
+		ContentType:  m.ContentType,
+		Width:        m.Width,
+		Height:       m.Height,
+		Url:          fmt.Sprintf("/api/media/%s", m.ID),
+		ThumbnailUrl: fmt.Sprintf("/api/media/%s/thumbnail", m.ID),
+	}
+}
+
+func (cfg *apiConfig) deleteBlobs(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		err := cfg.blobs.Delete(ctx, key)
+		if err != nil {
+			log.Printf("error deleting blob %s: %s\n", key, err)
+		}
+	}
+}
+
+// runMediaCleanup removes uploads that were never attached to a chirp.
+func (cfg *apiConfig) runMediaCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cfg.deleteOrphanedMedia(ctx)
+		}
+	}
+}
+
+func (cfg *apiConfig) deleteOrphanedMedia(ctx context.Context) {
+	orphans, err := cfg.dbQueries.GetOrphanedMedia(ctx, database.GetOrphanedMediaParams{
+		CreatedAt: time.Now().UTC().Add(-mediaOrphanAge),
+		Limit:     mediaCleanupBatch,
+	})
+	if err != nil {
+		log.Printf("error listing orphaned media: %s\n", err)
+		return
+	}
+	for _, m := range orphans {
+		cfg.deleteBlobs(ctx, m.StorageKey, m.ThumbnailKey)
+		err = cfg.dbQueries.DeleteMediaById(ctx, m.ID)
+		if err != nil {
+			log.Printf("error deleting media %s: %s\n", m.ID, err)
+		}
+	}
+}
@@ -0,0 +1,457 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type Conversation struct {
+	Id            uuid.UUID `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	ParticipantId uuid.UUID `json:"participant_id"`
+	UnreadCount   int64     `json:"unread_count"`
+}
+
+type Message struct {
+	Id             uuid.UUID  `json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ConversationId uuid.UUID  `json:"conversation_id"`
+	SenderId       uuid.UUID  `json:"sender_id"`
+	Body           string     `json:"body"`
+	Read           bool       `json:"read"`
+	ReadAt         *time.Time `json:"read_at,omitempty"`
+}
+
+type ReadReceipt struct {
+	ConversationId uuid.UUID `json:"conversation_id"`
+	ReaderId       uuid.UUID `json:"reader_id"`
+	ReadAt         time.Time `json:"read_at"`
+}
+
+type DMSettings struct {
+	DMsOpen bool `json:"dms_open"`
+}
+
+const (
+	eventMessageCreated = "message.created"
+	eventMessageDeleted = "message.deleted"
+	eventMessageRead    = "message.read"
+)
+
+const maxMessageLength = 2000
+
+func (cfg *apiConfig) handleStartConversation(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	payload := struct {
+		UserId uuid.UUID `json:"user_id"`
+	}{}
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if payload.UserId == dbUser.ID {
+		returnErrorResponse(w, "You can't message yourself")
+		return
+	}
+	recipient, err := cfg.dbQueries.GetUserById(req.Context(), payload.UserId)
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+	allowed, err := canMessage(req.Context(), cfg.dbQueries, dbUser.ID, recipient, uuid.Nil)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if !allowed {
+		returnForbidden(w)
+		return
+	}
+
+	userOne, userTwo := dbUser.ID, recipient.ID
+	if userTwo.String() < userOne.String() {
+		userOne, userTwo = userTwo, userOne
+	}
+	dbConversation, err := cfg.dbQueries.CreateConversation(req.Context(), database.CreateConversationParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserOneID: userOne,
+		UserTwoID: userTwo,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(dbConversationToResponse(dbConversation, dbUser.ID, 0))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleGetConversations(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	limit, err := queryLimit(req, defaultListLimit, maxListLimit)
+	if err != nil {
+		returnErrorResponse(w, "Invalid limit")
+		return
+	}
+	offset, err := queryOffset(req)
+	if err != nil {
+		returnErrorResponse(w, "Invalid offset")
+		return
+	}
+	dbConversations, err := cfg.dbQueries.GetConversationsForUser(req.Context(), database.GetConversationsForUserParams{
+		UserID:     dbUser.ID,
+		MaxResults: limit,
+		Skip:       offset,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	unread, err := cfg.dbQueries.GetUnreadMessageCounts(req.Context(), dbUser.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	unreadCounts := map[uuid.UUID]int64{}
+	for _, row := range unread {
+		unreadCounts[row.ConversationID] = row.Count
+	}
+
+	response := []Conversation{}
+	for _, c := range dbConversations {
+		response = append(response, dbConversationToResponse(c, dbUser.ID, unreadCounts[c.ID]))
+	}
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleGetMessages(w http.ResponseWriter, req *http.Request) {
+	_, dbConversation, ok := cfg.authenticateConversation(w, req)
+	if !ok {
+		return
+	}
+
+	limit, err := queryLimit(req, defaultListLimit, maxListLimit)
+	if err != nil {
+		returnErrorResponse(w, "Invalid limit")
+		return
+	}
+	offset, err := queryOffset(req)
+	if err != nil {
+		returnErrorResponse(w, "Invalid offset")
+		return
+	}
+	dbMessages, err := cfg.dbQueries.GetMessages(req.Context(), database.GetMessagesParams{
+		ConversationID: dbConversation.ID,
+		MaxResults:     limit,
+		Skip:           offset,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	response := []Message{}
+	for _, m := range dbMessages {
+		response = append(response, dbMessageToResponse(m))
+	}
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleSendMessage(w http.ResponseWriter, req *http.Request) {
+	dbUser, dbConversation, ok := cfg.authenticateConversation(w, req)
+	if !ok {
+		return
+	}
+
+	payload := struct {
+		Body string `json:"body"`
+	}{}
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if errorString := checkMessageBody(payload.Body); errorString != "" {
+		returnErrorResponse(w, errorString)
+		return
+	}
+
+	recipientId := conversationParticipant(dbConversation, dbUser.ID)
+	recipient, err := cfg.dbQueries.GetUserById(req.Context(), recipientId)
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+	allowed, err := canMessage(req.Context(), cfg.dbQueries, dbUser.ID, recipient, dbConversation.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if !allowed {
+		returnForbidden(w)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	dbMessage, err := qtx.CreateMessage(req.Context(), database.CreateMessageParams{
+		ID:             uuid.New(),
+		CreatedAt:      time.Now().UTC(),
+		ConversationID: dbConversation.ID,
+		SenderID:       dbUser.ID,
+		Body:           payload.Body,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = qtx.TouchConversation(req.Context(), database.TouchConversationParams{
+		ID:        dbConversation.ID,
+		UpdatedAt: dbMessage.CreatedAt,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	cfg.publishMessageCreated(req.Context(), recipientId, dbMessage.ID)
+
+	respBody, _ := encodeJson(dbMessageToResponse(dbMessage))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleDeleteMessage(w http.ResponseWriter, req *http.Request) {
+	dbUser, dbConversation, ok := cfg.authenticateConversation(w, req)
+	if !ok {
+		return
+	}
+
+	messageId, err := uuid.Parse(req.PathValue("message_id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	dbMessage, err := cfg.dbQueries.GetMessageById(req.Context(), messageId)
+	if err != nil || dbMessage.ConversationID != dbConversation.ID {
+		returnNotFound(w)
+		return
+	}
+	if dbMessage.SenderID != dbUser.ID {
+		returnForbidden(w)
+		return
+	}
+
+	err = cfg.dbQueries.DeleteMessage(req.Context(), dbMessage.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	// The body would only make the NOTIFY bigger; clients need the id to drop it.
+	deleted := dbMessageToResponse(dbMessage)
+	deleted.Body = ""
+	cfg.publishUserEvent(req.Context(), conversationParticipant(dbConversation, dbUser.ID), eventMessageDeleted, deleted)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleMarkConversationRead marks the other participant's messages as read
+// and sends them a read receipt.
+func (cfg *apiConfig) handleMarkConversationRead(w http.ResponseWriter, req *http.Request) {
+	dbUser, dbConversation, ok := cfg.authenticateConversation(w, req)
+	if !ok {
+		return
+	}
+
+	readAt := time.Now().UTC()
+	marked, err := cfg.dbQueries.MarkConversationRead(req.Context(), database.MarkConversationReadParams{
+		ReadAt:         sql.NullTime{Time: readAt, Valid: true},
+		ConversationID: dbConversation.ID,
+		ReaderID:       dbUser.ID,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if marked > 0 {
+		cfg.publishUserEvent(req.Context(), conversationParticipant(dbConversation, dbUser.ID), eventMessageRead, ReadReceipt{
+			ConversationId: dbConversation.ID,
+			ReaderId:       dbUser.ID,
+			ReadAt:         readAt,
+		})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetDMSettings(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	respBody, _ := encodeJson(DMSettings{DMsOpen: dbUser.DmsOpen})
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleUpdateDMSettings(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	payload := DMSettings{}
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	dbUser, err = cfg.dbQueries.SetUserDMsOpen(req.Context(), database.SetUserDMsOpenParams{
+		ID:        dbUser.ID,
+		DmsOpen:   payload.DMsOpen,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(DMSettings{DMsOpen: dbUser.DmsOpen})
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// checkMessageBody returns why a message body can't be sent, or "". Length is
+// counted in characters, not bytes.
+func checkMessageBody(body string) string {
+	if body == "" {
+		return standardError
+	}
+	if utf8.RuneCountInString(body) > maxMessageLength {
+		return "Message is too long"
+	}
+	return ""
+}
+
+// authenticateConversation loads the caller and the conversation in the path.
+// Conversations the caller isn't part of are reported as missing.
+func (cfg *apiConfig) authenticateConversation(w http.ResponseWriter, req *http.Request) (database.User, database.Conversation, bool) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return database.User{}, database.Conversation{}, false
+	}
+	conversationId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return database.User{}, database.Conversation{}, false
+	}
+	dbConversation, err := cfg.dbQueries.GetConversationById(req.Context(), conversationId)
+	if err != nil || (dbConversation.UserOneID != dbUser.ID && dbConversation.UserTwoID != dbUser.ID) {
+		returnNotFound(w)
+		return database.User{}, database.Conversation{}, false
+	}
+	return dbUser, dbConversation, true
+}
+
+// canMessage applies the DM rules: nobody can message across a block,
+// followers of the recipient can message them, everyone can if the recipient
+// opened their DMs, and once the recipient has written in a conversation the
+// sender may always reply.
+func canMessage(ctx context.Context, q *database.Queries, senderId uuid.UUID, recipient database.User, conversationId uuid.UUID) (bool, error) {
+	blocked, err := q.IsBlockedEitherWay(ctx, database.IsBlockedEitherWayParams{
+		UserID:  senderId,
+		OtherID: recipient.ID,
+	})
+	if err != nil || blocked {
+		return false, err
+	}
+	if recipient.DmsOpen {
+		return true, nil
+	}
+	following, err := q.IsFollowing(ctx, database.IsFollowingParams{
+		FollowerID: senderId,
+		FolloweeID: recipient.ID,
+	})
+	if err != nil || following {
+		return following, err
+	}
+	if conversationId == uuid.Nil {
+		return false, nil
+	}
+	return q.HasSentMessage(ctx, database.HasSentMessageParams{
+		ConversationID: conversationId,
+		SenderID:       recipient.ID,
+	})
+}
+
+// conversationParticipant returns the member of the conversation who isn't userId.
+func conversationParticipant(c database.Conversation, userId uuid.UUID) uuid.UUID {
+	if c.UserOneID == userId {
+		return c.UserTwoID
+	}
+	return c.UserOneID
+}
+
+func dbConversationToResponse(c database.Conversation, userId uuid.UUID, unread int64) Conversation {
+	return Conversation{
+		Id:            c.ID,
+		CreatedAt:     c.CreatedAt,
+		UpdatedAt:     c.UpdatedAt,
+		ParticipantId: conversationParticipant(c, userId),
+		UnreadCount:   unread,
+	}
+}
+
+func dbMessageToResponse(m database.Message) Message {
+	response := Message{
+		Id:             m.ID,
+		CreatedAt:      m.CreatedAt,
+		ConversationId: m.ConversationID,
+		SenderId:       m.SenderID,
+		Body:           m.Body,
+		Read:           m.ReadAt.Valid,
+	}
+	if m.ReadAt.Valid {
+		response.ReadAt = &m.ReadAt.Time
+	}
+	return response
+}
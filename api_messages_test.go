@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCheckMessageBody(t *testing.T) {
+	cases := map[string]bool{
+		"":                                      false,
+		"hi":                                    true,
+		strings.Repeat("é", maxMessageLength):   true,
+		strings.Repeat("<", maxMessageLength):   true,
+		strings.Repeat("a", maxMessageLength+1): false,
+	}
+	for body, valid := range cases {
+		if got := checkMessageBody(body) == ""; got != valid {
+			t.Errorf("checkMessageBody(%d runes) valid = %v, expected %v", len([]rune(body)), got, valid)
+		}
+	}
+}
+
+func TestLoadUserEventPassesDataThrough(t *testing.T) {
+	cfg := &apiConfig{}
+	userId := uuid.New()
+	event, err := cfg.loadUserEvent(context.Background(), `{"user_id":"`+userId.String()+`","type":"message.read","data":{"reader_id":"x"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Key != userId.String() || event.Type != eventMessageRead || string(event.Data) != `{"reader_id":"x"}` {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+// startConversation opens a conversation from the token's user with userId
+// and returns the status and the conversation.
+func startConversation(t *testing.T, url, token string, userId uuid.UUID) (int, Conversation) {
+	t.Helper()
+	conversation := Conversation{}
+	status := doRequest(t, http.MethodPost, url+"/api/conversations", token, map[string]uuid.UUID{"user_id": userId}, &conversation)
+	return status, conversation
+}
+
+func TestDirectMessageRules(t *testing.T) {
+	s, ts := testDBServer(t)
+	_, senderToken := createTestUser(t, s)
+	recipient, recipientToken := createTestUser(t, s)
+	_, strangerToken := createTestUser(t, s)
+
+	if status, _ := startConversation(t, ts.URL, senderToken, recipient.ID); status != http.StatusForbidden {
+		t.Errorf("messaging a stranger: expected 403, got %d", status)
+	}
+
+	doRequest(t, http.MethodPost, ts.URL+"/api/users/"+recipient.ID.String()+"/follow", senderToken, nil, nil)
+	status, conversation := startConversation(t, ts.URL, senderToken, recipient.ID)
+	if status != http.StatusOK {
+		t.Fatalf("messaging someone you follow: expected 200, got %d", status)
+	}
+	messagesURL := ts.URL + "/api/conversations/" + conversation.Id.String() + "/messages"
+
+	// Escaping makes this far bigger than a NOTIFY payload can be.
+	body := strings.Repeat("<", maxMessageLength)
+	if status := doRequest(t, http.MethodPost, messagesURL, senderToken, map[string]string{"body": body}, nil); status != http.StatusCreated {
+		t.Errorf("sending a long message: expected 201, got %d", status)
+	}
+	// The recipient doesn't follow back, but can answer a message they got.
+	if status := doRequest(t, http.MethodPost, messagesURL, recipientToken, map[string]string{"body": "hello"}, nil); status != http.StatusCreated {
+		t.Errorf("replying: expected 201, got %d", status)
+	}
+
+	doRequest(t, http.MethodPut, ts.URL+"/api/users/me/dm-settings", recipientToken, DMSettings{DMsOpen: true}, nil)
+	if status, _ := startConversation(t, ts.URL, strangerToken, recipient.ID); status != http.StatusOK {
+		t.Errorf("messaging a stranger with open DMs: expected 200, got %d", status)
+	}
+}
+
+func TestMarkConversationRead(t *testing.T) {
+	s, ts := testDBServer(t)
+	_, senderToken := createTestUser(t, s)
+	recipient, recipientToken := createTestUser(t, s)
+	doRequest(t, http.MethodPost, ts.URL+"/api/users/"+recipient.ID.String()+"/follow", senderToken, nil, nil)
+	_, conversation := startConversation(t, ts.URL, senderToken, recipient.ID)
+	conversationURL := ts.URL + "/api/conversations/" + conversation.Id.String()
+	for _, body := range []string{"one", "two"} {
+		doRequest(t, http.MethodPost, conversationURL+"/messages", senderToken, map[string]string{"body": body}, nil)
+	}
+
+	unread := func() int64 {
+		conversations := []Conversation{}
+		doRequest(t, http.MethodGet, ts.URL+"/api/conversations", recipientToken, nil, &conversations)
+		if len(conversations) != 1 {
+			t.Fatalf("expected one conversation, got %d", len(conversations))
+		}
+		return conversations[0].UnreadCount
+	}
+	if got := unread(); got != 2 {
+		t.Errorf("expected 2 unread messages, got %d", got)
+	}
+
+	// Only the recipient's reading marks messages read.
+	doRequest(t, http.MethodPost, conversationURL+"/read", senderToken, nil, nil)
+	if got := unread(); got != 2 {
+		t.Errorf("expected the sender's read to leave 2 unread, got %d", got)
+	}
+	if status := doRequest(t, http.MethodPost, conversationURL+"/read", recipientToken, nil, nil); status != http.StatusNoContent {
+		t.Fatalf("read: expected 204, got %d", status)
+	}
+	if got := unread(); got != 0 {
+		t.Errorf("expected no unread messages, got %d", got)
+	}
+
+	messages := []Message{}
+	doRequest(t, http.MethodGet, conversationURL+"/messages", senderToken, nil, &messages)
+	for _, m := range messages {
+		if !m.Read || m.ReadAt == nil {
+			t.Errorf("expected message %q to be read", m.Body)
+		}
+	}
+}
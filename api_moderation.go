@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type Report struct {
+	Id          uuid.UUID     `json:"id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	ChirpId     uuid.NullUUID `json:"chirp_id"`
+	ReporterId  uuid.NullUUID `json:"reporter_id"`
+	Reason      string        `json:"reason"`
+	Details     string        `json:"details"`
+	Status      string        `json:"status"`
+	ModeratorId uuid.NullUUID `json:"moderator_id"`
+	Resolution  string        `json:"resolution,omitempty"`
+	ResolvedAt  *time.Time    `json:"resolved_at,omitempty"`
+}
+
+type ReportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+type ResolveReportRequest struct {
+	Action       string `json:"action"`
+	Notes        string `json:"notes"`
+	SuspendHours int    `json:"suspend_hours"`
+}
+
+type ModerationAction struct {
+	Id          uuid.UUID     `json:"id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	ModeratorId uuid.UUID     `json:"moderator_id"`
+	Action      string        `json:"action"`
+	ReportId    uuid.NullUUID `json:"report_id"`
+	ChirpId     uuid.NullUUID `json:"chirp_id"`
+	UserId      uuid.NullUUID `json:"user_id"`
+	Notes       string        `json:"notes"`
+}
+
+const (
+	reportStatusOpen     = "open"
+	reportStatusClaimed  = "claimed"
+	reportStatusResolved = "resolved"
+
+	moderationClaim         = "claim"
+	moderationDismiss       = "dismiss"
+	moderationHideChirp     = "hide_chirp"
+	moderationDeleteChirp   = "delete_chirp"
+	moderationSuspendAuthor = "suspend_author"
+
+	// reportReasonFilter marks reports raised by the profanity filter rather than a user.
+	reportReasonFilter = "filter"
+)
+
+var reportReasons = map[string]bool{
+	"spam":           true,
+	"harassment":     true,
+	"hate":           true,
+	"violence":       true,
+	"sexual":         true,
+	"self_harm":      true,
+	"misinformation": true,
+	"other":          true,
+}
+
+var resolveActions = map[string]bool{
+	moderationDismiss:       true,
+	moderationHideChirp:     true,
+	moderationDeleteChirp:   true,
+	moderationSuspendAuthor: true,
+}
+
+func (cfg *apiConfig) handleReportChirp(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	chirpId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	dbChirp, err := cfg.dbQueries.GetPublicChirpById(req.Context(), database.GetPublicChirpByIdParams{
+		ID:       chirpId,
+		ViewerID: dbUser.ID,
+	})
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+	if dbChirp.UserID == dbUser.ID {
+		returnErrorResponse(w, "Cannot report your own chirp")
+		return
+	}
+
+	payload := ReportRequest{}
+	err = json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil || !reportReasons[payload.Reason] {
+		returnErrorResponse(w, "Invalid report reason")
+		return
+	}
+
+	now := time.Now().UTC()
+	dbReport, err := cfg.dbQueries.CreateReport(req.Context(), database.CreateReportParams{
+		ID:         uuid.New(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		ChirpID:    uuid.NullUUID{UUID: dbChirp.ID, Valid: true},
+		ReporterID: uuid.NullUUID{UUID: dbUser.ID, Valid: true},
+		Reason:     payload.Reason,
+		Details:    payload.Details,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			returnConflict(w)
+			return
+		}
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(dbReportToResponse(dbReport))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleGetReports(w http.ResponseWriter, req *http.Request) {
+	_, ok := cfg.authenticateModerator(w, req)
+	if !ok {
+		return
+	}
+
+	status := req.URL.Query().Get("status")
+	if status == "" {
+		status = reportStatusOpen
+	}
+	if status != reportStatusOpen && status != reportStatusClaimed && status != reportStatusResolved {
+		returnBadRequest(w)
+		return
+	}
+
+	dbReports, err := cfg.dbQueries.GetReportsByStatus(req.Context(), status)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	reports := []Report{}
+	for _, r := range dbReports {
+		reports = append(reports, dbReportToResponse(r))
+	}
+	respBody, _ := encodeJson(reports)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleClaimReport(w http.ResponseWriter, req *http.Request) {
+	moderator, ok := cfg.authenticateModerator(w, req)
+	if !ok {
+		return
+	}
+
+	reportId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	_, err = cfg.dbQueries.GetReportById(req.Context(), reportId)
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	dbReport, err := qtx.ClaimReport(req.Context(), database.ClaimReportParams{
+		ID:          reportId,
+		ModeratorID: uuid.NullUUID{UUID: moderator.ID, Valid: true},
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			returnConflict(w)
+			return
+		}
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	err = recordModerationAction(req.Context(), qtx, database.CreateModerationActionParams{
+		ModeratorID: moderator.ID,
+		Action:      moderationClaim,
+		ReportID:    uuid.NullUUID{UUID: dbReport.ID, Valid: true},
+		ChirpID:     dbReport.ChirpID,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(dbReportToResponse(dbReport))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleResolveReport(w http.ResponseWriter, req *http.Request) {
+	moderator, ok := cfg.authenticateModerator(w, req)
+	if !ok {
+		return
+	}
+
+	reportId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	dbReport, err := cfg.dbQueries.GetReportById(req.Context(), reportId)
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+	if dbReport.Status != reportStatusClaimed || dbReport.ModeratorID.UUID != moderator.ID {
+		returnConflict(w)
+		return
+	}
+
+	payload := ResolveReportRequest{}
+	err = json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil || !resolveActions[payload.Action] {
+		returnErrorResponse(w, "Invalid moderation action")
+		return
+	}
+	if payload.Action != moderationDismiss && !dbReport.ChirpID.Valid {
+		returnErrorResponse(w, "Reported chirp no longer exists")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	now := time.Now().UTC()
+	dbReport, err = qtx.ResolveReport(req.Context(), database.ResolveReportParams{
+		ID:          dbReport.ID,
+		Resolution:  sql.NullString{String: payload.Action, Valid: true},
+		ResolvedAt:  sql.NullTime{Time: now, Valid: true},
+		UpdatedAt:   now,
+		ModeratorID: uuid.NullUUID{UUID: moderator.ID, Valid: true},
+	})
+	if err != nil {
+		// Resolved or re-claimed since we checked.
+		if errors.Is(err, sql.ErrNoRows) {
+			returnConflict(w)
+			return
+		}
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	target := uuid.NullUUID{}
+	if payload.Action != moderationDismiss {
+		target, err = applyModerationAction(req.Context(), qtx, moderator.ID, payload, dbReport.ChirpID.UUID)
+		if err != nil {
+			returnErrorResponse(w, standardError)
+			return
+		}
+	}
+
+	err = recordModerationAction(req.Context(), qtx, database.CreateModerationActionParams{
+		ModeratorID: moderator.ID,
+		Action:      payload.Action,
+		ReportID:    uuid.NullUUID{UUID: dbReport.ID, Valid: true},
+		ChirpID:     dbReport.ChirpID,
+		UserID:      target,
+		Notes:       payload.Notes,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(dbReportToResponse(dbReport))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// applyModerationAction enforces a resolution against the reported chirp and
+// returns the affected user for the audit trail.
+func applyModerationAction(ctx context.Context, qtx *database.Queries, moderatorId uuid.UUID, payload ResolveReportRequest, chirpId uuid.UUID) (uuid.NullUUID, error) {
+	// Chirps in the trash can still be restored, so they're moderated too.
+	dbChirp, err := qtx.GetAnyChirpById(ctx, chirpId)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	author := uuid.NullUUID{UUID: dbChirp.UserID, Valid: true}
+	now := time.Now().UTC()
+
+	switch payload.Action {
+	case moderationHideChirp, moderationDeleteChirp:
+		// Every other report against the chirp is settled by the same action.
+		err = qtx.ResolveOpenReportsByChirpId(ctx, database.ResolveOpenReportsByChirpIdParams{
+			ChirpID:     uuid.NullUUID{UUID: dbChirp.ID, Valid: true},
+			ModeratorID: uuid.NullUUID{UUID: moderatorId, Valid: true},
+			Resolution:  sql.NullString{String: payload.Action, Valid: true},
+			ResolvedAt:  sql.NullTime{Time: now, Valid: true},
+			UpdatedAt:   now,
+		})
+		if err != nil {
+			return author, err
+		}
+		// Deleted chirps go to the trash like the author's own deletes, but
+		// are hidden too so the author can't restore them.
+		_, err = qtx.HideChirp(ctx, database.HideChirpParams{
+			ID:        dbChirp.ID,
+			HiddenAt:  sql.NullTime{Time: now, Valid: true},
+			UpdatedAt: now,
+		})
+		if err == nil && payload.Action == moderationDeleteChirp && !dbChirp.DeletedAt.Valid {
+			dbChirp, err = qtx.SoftDeleteChirp(ctx, database.SoftDeleteChirpParams{
+				ID:        dbChirp.ID,
+				DeletedAt: sql.NullTime{Time: now, Valid: true},
+			})
+			if err == nil {
+				err = enqueueWebhookEvent(ctx, qtx, eventChirpDeleted, dbChirp.UserID, dbChirpToResponse(dbChirp))
+			}
+		}
+		// Hidden chirps disappear from live streams the same way deleted ones do.
+		if err == nil {
+			err = recordStreamEvent(ctx, qtx, eventChirpDeleted, dbChirpToResponse(dbChirp))
+		}
+	case moderationSuspendAuthor:
+		_, err = suspendUser(ctx, qtx, dbChirp.UserID, suspensionDuration(payload.SuspendHours), payload.Notes)
+	}
+	return author, err
+}
+
+func recordModerationAction(ctx context.Context, qtx *database.Queries, params database.CreateModerationActionParams) error {
+	params.ID = uuid.New()
+	params.CreatedAt = time.Now().UTC()
+	_, err := qtx.CreateModerationAction(ctx, params)
+	return err
+}
+
+func (cfg *apiConfig) handleGetModerationActions(w http.ResponseWriter, req *http.Request) {
+	_, ok := cfg.authenticateModerator(w, req)
+	if !ok {
+		return
+	}
+
+	dbActions, err := cfg.dbQueries.GetModerationActions(req.Context())
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	actions := []ModerationAction{}
+	for _, a := range dbActions {
+		actions = append(actions, ModerationAction{
+			Id:          a.ID,
+			CreatedAt:   a.CreatedAt,
+			ModeratorId: a.ModeratorID,
+			Action:      a.Action,
+			ReportId:    a.ReportID,
+			ChirpId:     a.ChirpID,
+			UserId:      a.UserID,
+			Notes:       a.Notes,
+		})
+	}
+	respBody, _ := encodeJson(actions)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func dbReportToResponse(r database.Report) Report {
+	report := Report{
+		Id:          r.ID,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+		ChirpId:     r.ChirpID,
+		ReporterId:  r.ReporterID,
+		Reason:      r.Reason,
+		Details:     r.Details,
+		Status:      r.Status,
+		ModeratorId: r.ModeratorID,
+		Resolution:  r.Resolution.String,
+	}
+	if r.ResolvedAt.Valid {
+		report.ResolvedAt = &r.ResolvedAt.Time
+	}
+	return report
+}
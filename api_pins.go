@@ -0,0 +1,132 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlePinChirp(w http.ResponseWriter, req *http.Request) {
+	dbChirp, ok := cfg.authenticateChirpOwner(w, req)
+	if !ok {
+		return
+	}
+	if dbChirp.PinnedAt.Valid {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	dbUser, err := cfg.dbQueries.GetUserById(req.Context(), dbChirp.UserID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	// Concurrent pins by the same author wait here, so each one counts the
+	// pins committed before it.
+	err = qtx.LockUser(req.Context(), dbUser.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	pinned, err := qtx.CountPinnedChirps(req.Context(), dbUser.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	maxPinned := cfg.entitlements(dbUser).MaxPinnedChirps
+	if pinned >= int64(maxPinned) {
+		returnErrorResponse(w, fmt.Sprintf("You can pin at most %d chirps", maxPinned))
+		return
+	}
+
+	_, err = qtx.PinChirp(req.Context(), database.PinChirpParams{
+		ID:       dbChirp.ID,
+		PinnedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		returnNotFound(w)
+		return
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUnpinChirp(w http.ResponseWriter, req *http.Request) {
+	dbChirp, ok := cfg.authenticateChirpOwner(w, req)
+	if !ok {
+		return
+	}
+	err := cfg.dbQueries.UnpinChirp(req.Context(), dbChirp.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AuthorFeed is GET /api/chirps?author_id=: the author's pinned chirps, most
+// recently pinned first, then their chirps in the requested order. Pinned
+// chirps are listed in both.
+type AuthorFeed struct {
+	Pinned []Chirp `json:"pinned"`
+	Chirps []Chirp `json:"chirps"`
+}
+
+func newAuthorFeed(pinned, chirps []Chirp) AuthorFeed {
+	if pinned == nil {
+		pinned = []Chirp{}
+	}
+	if chirps == nil {
+		chirps = []Chirp{}
+	}
+	return AuthorFeed{Pinned: pinned, Chirps: chirps}
+}
+
+// returnAuthorFeed adds the pinned chirps that viewerId can see to a page of
+// the author's chirps.
+func (cfg *apiConfig) returnAuthorFeed(w http.ResponseWriter, req *http.Request, authorId, viewerId uuid.UUID, dbChirps []database.Chirp) {
+	dbPinned, err := cfg.dbQueries.GetPinnedChirps(req.Context(), database.GetPinnedChirpsParams{
+		UserID:   authorId,
+		ViewerID: viewerId,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	pinned, err := cfg.chirpsForViewer(req.Context(), dbPinned, viewerId)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	chirps, err := cfg.chirpsForViewer(req.Context(), dbChirps, viewerId)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(newAuthorFeed(pinned, chirps))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
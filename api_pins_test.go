@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentPinsStayWithinTheLimit(t *testing.T) {
+	s, ts := testDBServer(t)
+	_, token := createTestUser(t, s)
+	chirps := []Chirp{}
+	for range 4 {
+		chirps = append(chirps, postChirp(t, ts, token, ChirpRequest{Body: "pin me"}))
+	}
+
+	statuses := make([]int, len(chirps))
+	wg := sync.WaitGroup{}
+	for i, chirp := range chirps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodPut, ts.URL+"/api/chirps/"+chirp.Id.String()+"/pin", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return
+			}
+			resp.Body.Close()
+			statuses[i] = resp.StatusCode
+		}()
+	}
+	wg.Wait()
+
+	pinned := 0
+	for _, status := range statuses {
+		switch status {
+		case http.StatusNoContent:
+			pinned++
+		case http.StatusBadRequest:
+		default:
+			t.Errorf("unexpected status %d", status)
+		}
+	}
+	if max := defaultTiers[tierFree].MaxPinnedChirps; pinned != max {
+		t.Errorf("expected %d pinned chirps, got %d", max, pinned)
+	}
+}
+
+func TestUnpinFreesASlot(t *testing.T) {
+	s, ts := testDBServer(t)
+	author, token := createTestUser(t, s)
+	first := postChirp(t, ts, token, ChirpRequest{Body: "first"})
+	second := postChirp(t, ts, token, ChirpRequest{Body: "second"})
+
+	pinURL := func(c Chirp) string { return ts.URL + "/api/chirps/" + c.Id.String() + "/pin" }
+	if status := doRequest(t, http.MethodPut, pinURL(first), token, nil, nil); status != http.StatusNoContent {
+		t.Fatalf("pin: expected 204, got %d", status)
+	}
+	if status := doRequest(t, http.MethodPut, pinURL(second), token, nil, nil); status != http.StatusBadRequest {
+		t.Fatalf("pin over the limit: expected 400, got %d", status)
+	}
+	if status := doRequest(t, http.MethodDelete, pinURL(first), token, nil, nil); status != http.StatusNoContent {
+		t.Fatalf("unpin: expected 204, got %d", status)
+	}
+	if status := doRequest(t, http.MethodPut, pinURL(second), token, nil, nil); status != http.StatusNoContent {
+		t.Fatalf("pin after unpin: expected 204, got %d", status)
+	}
+
+	feed := AuthorFeed{}
+	doRequest(t, http.MethodGet, ts.URL+"/api/chirps?author_id="+author.ID.String(), "", nil, &feed)
+	if got := chirpIds(feed.Pinned); !slices.Equal(got, chirpIds([]Chirp{second})) {
+		t.Errorf("expected only the second chirp pinned, got %v", got)
+	}
+}
+
+func TestAuthorFeedOrdersPinnedChirps(t *testing.T) {
+	s, ts := testDBServer(t)
+	s.Config.Tiers = map[string]TierLimits{tierFree: {MaxPinnedChirps: 3}}
+	author, token := createTestUser(t, s)
+	oldest := postChirp(t, ts, token, ChirpRequest{Body: "oldest"})
+	middle := postChirp(t, ts, token, ChirpRequest{Body: "middle"})
+	newest := postChirp(t, ts, token, ChirpRequest{Body: "newest"})
+
+	for _, chirp := range []Chirp{middle, oldest} {
+		status := doRequest(t, http.MethodPut, ts.URL+"/api/chirps/"+chirp.Id.String()+"/pin", token, nil, nil)
+		if status != http.StatusNoContent {
+			t.Fatalf("pin: expected 204, got %d", status)
+		}
+	}
+
+	feed := AuthorFeed{}
+	status := doRequest(t, http.MethodGet, ts.URL+"/api/chirps?author_id="+author.ID.String()+"&sort=desc", "", nil, &feed)
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	// Most recently pinned first, while the feed keeps its own order.
+	if got, expected := chirpIds(feed.Pinned), chirpIds([]Chirp{oldest, middle}); !slices.Equal(got, expected) {
+		t.Errorf("expected pinned %v, got %v", expected, got)
+	}
+	if got, expected := chirpIds(feed.Chirps), chirpIds([]Chirp{newest, middle, oldest}); !slices.Equal(got, expected) {
+		t.Errorf("expected chirps %v, got %v", expected, got)
+	}
+}
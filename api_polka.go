@@ -1,8 +1,11 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -17,54 +20,155 @@ type PolkaRequest struct {
 }
 
 type PolkaRequestData struct {
-	UserId uuid.UUID `json:"user_id"`
+	UserId    uuid.UUID  `json:"user_id"`
+	PeriodEnd *time.Time `json:"period_end"`
 }
 
 const (
-	validPolkaWebhookEvent = "user.upgraded"
+	polkaUserUpgraded          = "user.upgraded"
+	polkaUserDowngraded        = "user.downgraded"
+	polkaSubscriptionRenewed   = "subscription.renewed"
+	polkaSubscriptionCancelled = "subscription.cancelled"
+	polkaPaymentFailed         = "payment.failed"
 )
 
+const (
+	polkaSource           = "polka"
+	polkaSignatureHeader  = "X-Polka-Signature"
+	polkaDeliveryHeader   = "X-Polka-Delivery-Id"
+	defaultPolkaTolerance = 5 * time.Minute
+	maxWebhookBody        = 1 << 20
+	webhookEventPending   = "pending"
+	webhookEventProcessed = "processed"
+	webhookEventIgnored   = "ignored"
+	webhookEventFailed    = "failed"
+)
+
+var validPolkaWebhookEvents = map[string]bool{
+	polkaUserUpgraded:          true,
+	polkaUserDowngraded:        true,
+	polkaSubscriptionRenewed:   true,
+	polkaSubscriptionCancelled: true,
+	polkaPaymentFailed:         true,
+}
+
+// handlePolkaWebhooks verifies the HMAC signature over the raw body, stores
+// the delivery and applies it once. Redelivered IDs that were already
+// processed are acknowledged without running them again.
 func (cfg *apiConfig) handlePolkaWebhooks(w http.ResponseWriter, req *http.Request) {
-	apiKey, err := auth.GetAPIKey(req.Header)
-	if err != nil || apiKey != cfg.PolkaKey {
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxWebhookBody))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	err = auth.VerifyWebhook(cfg.PolkaKey, req.Header.Get(polkaSignatureHeader), body, cfg.PolkaTolerance, time.Now())
+	if err != nil {
 		returnUnauthorized(w)
 		return
 	}
 
-	decoder := json.NewDecoder(req.Body)
+	deliveryId := req.Header.Get(polkaDeliveryHeader)
+	if deliveryId == "" {
+		returnErrorResponse(w, "Missing delivery id")
+		return
+	}
 	requestData := PolkaRequest{}
-	err = decoder.Decode(&requestData)
+	err = json.Unmarshal(body, &requestData)
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
 	}
-	if requestData.Event != validPolkaWebhookEvent {
-		w.WriteHeader(http.StatusNoContent)
+
+	now := time.Now().UTC()
+	event, err := cfg.dbQueries.RecordWebhookEvent(req.Context(), database.RecordWebhookEventParams{
+		ID:         uuid.New(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Source:     polkaSource,
+		DeliveryID: deliveryId,
+		Event:      requestData.Event,
+		Payload:    string(body),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
 		return
 	}
 
-	params := database.UpgradeUserToRedParams{
-		ID: requestData.Data.UserId,
-		IsChirpyRed: sql.NullBool{
-			Bool:  true,
-			Valid: true,
-		},
-		UpdatedAt: time.Now().UTC(),
+	_, err = cfg.processPolkaEvent(req.Context(), event.ID, false)
+	if errors.Is(err, errNoSubscription) {
+		returnNotFound(w)
+		return
 	}
-
-	dbUser, err := cfg.dbQueries.GetUserById(req.Context(), requestData.Data.UserId)
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
 	}
 
-	if dbUser.IsChirpyRed.Valid && !dbUser.IsChirpyRed.Bool {
-		_, err := cfg.dbQueries.UpgradeUserToRed(req.Context(), params)
-		if err != nil {
-			returnErrorResponse(w, standardError)
-			return
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// processPolkaEvent applies a stored delivery inside a transaction that holds
+// the event row lock, so concurrent redeliveries can't both run it. Already
+// processed or ignored events are skipped unless replay is set.
+func (cfg *apiConfig) processPolkaEvent(ctx context.Context, eventId uuid.UUID, replay bool) (database.WebhookEvent, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.WebhookEvent{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	event, err := qtx.LockWebhookEvent(ctx, eventId)
+	if err != nil {
+		return database.WebhookEvent{}, err
+	}
+	if !replay && event.Status != webhookEventPending && event.Status != webhookEventFailed {
+		return event, nil
+	}
+
+	status := webhookEventProcessed
+	payload := PolkaRequest{}
+	err = json.Unmarshal([]byte(event.Payload), &payload)
+	if err == nil && !validPolkaWebhookEvents[payload.Event] {
+		status = webhookEventIgnored
+	} else if err == nil {
+		err = cfg.applyPolkaPayload(ctx, qtx, payload)
+	}
+	if err != nil {
+		tx.Rollback()
+		_, markErr := markWebhookEvent(ctx, cfg.dbQueries, event.ID, webhookEventFailed, err.Error())
+		if markErr != nil {
+			return event, markErr
 		}
+		return event, err
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	event, err = markWebhookEvent(ctx, qtx, event.ID, status, "")
+	if err != nil {
+		return event, err
+	}
+	return event, tx.Commit()
+}
+
+func (cfg *apiConfig) applyPolkaPayload(ctx context.Context, qtx *database.Queries, payload PolkaRequest) error {
+	_, err := qtx.GetUserById(ctx, payload.Data.UserId)
+	if err != nil {
+		return fmt.Errorf("unknown user %s: %w", payload.Data.UserId, err)
+	}
+	return cfg.applyPolkaEvent(ctx, qtx, payload.Event, payload.Data)
+}
+
+func markWebhookEvent(ctx context.Context, q *database.Queries, id uuid.UUID, status, errorText string) (database.WebhookEvent, error) {
+	now := time.Now().UTC()
+	params := database.MarkWebhookEventParams{
+		ID:        id,
+		Status:    status,
+		Error:     errorText,
+		UpdatedAt: now,
+	}
+	if status != webhookEventFailed {
+		params.ProcessedAt.Time = now
+		params.ProcessedAt.Valid = true
+	}
+	return q.MarkWebhookEvent(ctx, params)
 }
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/auth"
+)
+
+func TestPolkaWebhooksRejectedWithoutKey(t *testing.T) {
+	_, ts := testServer(t)
+	body := `{"event":"user.upgraded","data":{"user_id":"3311741c-680c-4546-99f3-fc9efac2036c"}}`
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/api/polka/webhooks", strings.NewReader(body))
+	req.Header.Set(polkaSignatureHeader, auth.SignWebhook("", []byte(body), time.Now()))
+	req.Header.Set(polkaDeliveryHeader, "delivery-1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /api/polka/webhooks: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a webhook signed with an empty key to be refused, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/chirptext"
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type PollRequest struct {
+	Options  []string  `json:"options"`
+	ClosesAt time.Time `json:"closes_at"`
+}
+
+// Poll is a chirp's poll as seen by one viewer. Vote counts are only filled
+// in once the viewer has voted or the poll has closed.
+type Poll struct {
+	ClosesAt      time.Time    `json:"closes_at"`
+	Closed        bool         `json:"closed"`
+	Options       []PollOption `json:"options"`
+	TotalVotes    *int64       `json:"total_votes,omitempty"`
+	VotedOptionId *uuid.UUID   `json:"voted_option_id,omitempty"`
+}
+
+type PollOption struct {
+	Id    uuid.UUID `json:"id"`
+	Label string    `json:"label"`
+	Votes *int64    `json:"votes,omitempty"`
+}
+
+type VoteRequest struct {
+	OptionId uuid.UUID `json:"option_id"`
+}
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 25
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+// checkPollRequest validates a poll and runs its options through the
+// profanity filter, masking them in place. It returns a client error, or "".
+func (cfg *apiConfig) checkPollRequest(poll *PollRequest) string {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return fmt.Sprintf("A poll needs %d to %d options", minPollOptions, maxPollOptions)
+	}
+	seen := map[string]bool{}
+	for i, option := range poll.Options {
+		option = strings.TrimSpace(chirptext.Normalize(option))
+		if option == "" {
+			return "Poll options can't be empty"
+		}
+		if chirptext.Length(option) > maxPollOptionLength {
+			return fmt.Sprintf("Poll options can be at most %d characters", maxPollOptionLength)
+		}
+		if seen[strings.ToLower(option)] {
+			return "Poll options must be different"
+		}
+		seen[strings.ToLower(option)] = true
+
+		moderated := cfg.profanity.Check(option)
+		if moderated.Rejected {
+			return "Chirp contains prohibited language"
+		}
+		poll.Options[i] = moderated.Text
+	}
+	now := time.Now()
+	if !poll.ClosesAt.After(now) {
+		return "closes_at must be in the future"
+	}
+	if poll.ClosesAt.After(now.Add(maxPollDuration)) {
+		return "Polls can run for at most 7 days"
+	}
+	return ""
+}
+
+// createPoll stores a checked poll for a new chirp. It runs inside the
+// chirp's transaction.
+func createPoll(ctx context.Context, q *database.Queries, chirpId uuid.UUID, reqPoll PollRequest) (*Poll, error) {
+	dbPoll, err := q.CreatePoll(ctx, database.CreatePollParams{
+		ChirpID:  chirpId,
+		ClosesAt: reqPoll.ClosesAt.UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	poll := &Poll{ClosesAt: dbPoll.ClosesAt}
+	for i, label := range reqPoll.Options {
+		option, err := q.CreatePollOption(ctx, database.CreatePollOptionParams{
+			ID:       uuid.New(),
+			ChirpID:  chirpId,
+			Position: int32(i),
+			Label:    label,
+		})
+		if err != nil {
+			return nil, err
+		}
+		poll.Options = append(poll.Options, PollOption{Id: option.ID, Label: option.Label})
+	}
+	return poll, nil
+}
+
+func (cfg *apiConfig) handleVote(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	chirpId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+	dbChirp, err := cfg.dbQueries.GetPublicChirpById(req.Context(), database.GetPublicChirpByIdParams{
+		ID:       chirpId,
+		ViewerID: dbUser.ID,
+	})
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+	dbPoll, err := cfg.dbQueries.GetPollByChirpId(req.Context(), dbChirp.ID)
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+	if !time.Now().UTC().Before(dbPoll.ClosesAt) {
+		returnErrorResponse(w, "Poll is closed")
+		return
+	}
+
+	reqVote := VoteRequest{}
+	err = json.NewDecoder(req.Body).Decode(&reqVote)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	option, err := cfg.dbQueries.GetPollOption(req.Context(), database.GetPollOptionParams{
+		ID:      reqVote.OptionId,
+		ChirpID: dbChirp.ID,
+	})
+	if err != nil {
+		returnErrorResponse(w, "Invalid option")
+		return
+	}
+	_, err = cfg.dbQueries.CreatePollVote(req.Context(), database.CreatePollVoteParams{
+		ChirpID:   dbChirp.ID,
+		UserID:    dbUser.ID,
+		OptionID:  option.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		returnConflict(w)
+		return
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	chirps := []Chirp{dbChirpToResponse(dbChirp)}
+	err = cfg.loadChirpDetails(req.Context(), chirps, dbUser.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	respBody, _ := encodeJson(chirps[0])
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// pollResultsVisible reports whether a viewer gets the vote counts: only once
+// they've voted or the poll has closed, so results can't sway their vote.
+func pollResultsVisible(poll *Poll) bool {
+	return poll.Closed || poll.VotedOptionId != nil
+}
+
+// loadChirpPolls fills in the polls of a page of chirps as viewerId sees them.
+func (cfg *apiConfig) loadChirpPolls(ctx context.Context, chirps []Chirp, viewerId uuid.UUID) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(chirps))
+	for _, c := range chirps {
+		ids = append(ids, c.Id)
+	}
+	dbPolls, err := cfg.dbQueries.GetPollsForChirps(ctx, ids)
+	if err != nil || len(dbPolls) == 0 {
+		return err
+	}
+
+	pollIds := make([]uuid.UUID, 0, len(dbPolls))
+	polls := map[uuid.UUID]*Poll{}
+	now := time.Now().UTC()
+	for _, p := range dbPolls {
+		pollIds = append(pollIds, p.ChirpID)
+		polls[p.ChirpID] = &Poll{
+			ClosesAt: p.ClosesAt,
+			Closed:   !now.Before(p.ClosesAt),
+			Options:  []PollOption{},
+		}
+	}
+	if viewerId != uuid.Nil {
+		votes, err := cfg.dbQueries.GetPollVotesByUser(ctx, database.GetPollVotesByUserParams{
+			UserID:   viewerId,
+			ChirpIds: pollIds,
+		})
+		if err != nil {
+			return err
+		}
+		for _, v := range votes {
+			polls[v.ChirpID].VotedOptionId = &v.OptionID
+		}
+	}
+	counts, err := cfg.dbQueries.GetPollVoteCounts(ctx, pollIds)
+	if err != nil {
+		return err
+	}
+	votesByOption := map[uuid.UUID]int64{}
+	for _, c := range counts {
+		votesByOption[c.OptionID] = c.Count
+	}
+	options, err := cfg.dbQueries.GetPollOptionsForChirps(ctx, pollIds)
+	if err != nil {
+		return err
+	}
+	for _, o := range options {
+		poll := polls[o.ChirpID]
+		option := PollOption{Id: o.ID, Label: o.Label}
+		if pollResultsVisible(poll) {
+			votes := votesByOption[o.ID]
+			option.Votes = &votes
+			if poll.TotalVotes == nil {
+				poll.TotalVotes = new(int64)
+			}
+			*poll.TotalVotes += votes
+		}
+		poll.Options = append(poll.Options, option)
+	}
+
+	for i := range chirps {
+		chirps[i].Poll = polls[chirps[i].Id]
+	}
+	return nil
+}
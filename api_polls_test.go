@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/filter"
+	"github.com/google/uuid"
+)
+
+func TestCheckPollRequest(t *testing.T) {
+	cfg := &apiConfig{profanity: filter.New(defaultProfaneWords)}
+	closesAt := time.Now().Add(time.Hour)
+	cases := []struct {
+		name  string
+		poll  PollRequest
+		valid bool
+	}{
+		{"valid", PollRequest{Options: []string{"yes", "no"}, ClosesAt: closesAt}, true},
+		{"one option", PollRequest{Options: []string{"yes"}, ClosesAt: closesAt}, false},
+		{"five options", PollRequest{Options: []string{"a", "b", "c", "d", "e"}, ClosesAt: closesAt}, false},
+		{"blank option", PollRequest{Options: []string{"yes", " "}, ClosesAt: closesAt}, false},
+		{"long option", PollRequest{Options: []string{"yes", strings.Repeat("n", maxPollOptionLength+1)}, ClosesAt: closesAt}, false},
+		{"duplicate options", PollRequest{Options: []string{"Yes", "yes"}, ClosesAt: closesAt}, false},
+		{"closed", PollRequest{Options: []string{"yes", "no"}, ClosesAt: time.Now()}, false},
+		{"too long", PollRequest{Options: []string{"yes", "no"}, ClosesAt: time.Now().Add(maxPollDuration + time.Hour)}, false},
+	}
+	for _, c := range cases {
+		if errorString := cfg.checkPollRequest(&c.poll); (errorString == "") != c.valid {
+			t.Errorf("%s: expected valid %v, got %q", c.name, c.valid, errorString)
+		}
+	}
+}
+
+func TestPollResultsVisible(t *testing.T) {
+	optionId := uuid.New()
+	cases := []struct {
+		name    string
+		poll    Poll
+		visible bool
+	}{
+		{"open, not voted", Poll{}, false},
+		{"open, voted", Poll{VotedOptionId: &optionId}, true},
+		{"closed, not voted", Poll{Closed: true}, true},
+	}
+	for _, c := range cases {
+		if got := pollResultsVisible(&c.poll); got != c.visible {
+			t.Errorf("%s: expected %v, got %v", c.name, c.visible, got)
+		}
+	}
+}
+
+// getPoll reads a chirp's poll as token's user, who may be anonymous.
+func getPoll(t *testing.T, url, token string, chirpId uuid.UUID) *Poll {
+	t.Helper()
+	chirp := Chirp{}
+	status := doRequest(t, http.MethodGet, url+"/api/chirps/"+chirpId.String(), token, nil, &chirp)
+	if status != http.StatusOK || chirp.Poll == nil {
+		t.Fatalf("expected a chirp with a poll, got %d", status)
+	}
+	return chirp.Poll
+}
+
+func TestPollResultsAreHiddenUntilVotingOrClosing(t *testing.T) {
+	s, ts := testDBServer(t)
+	_, authorToken := createTestUser(t, s)
+	_, voterToken := createTestUser(t, s)
+	_, otherToken := createTestUser(t, s)
+	chirp := postChirp(t, ts, authorToken, ChirpRequest{
+		Body: "tabs or spaces?",
+		Poll: &PollRequest{Options: []string{"tabs", "spaces"}, ClosesAt: time.Now().Add(time.Hour)},
+	})
+	voteURL := ts.URL + "/api/chirps/" + chirp.Id.String() + "/vote"
+
+	if poll := getPoll(t, ts.URL, voterToken, chirp.Id); poll.TotalVotes != nil || poll.Options[0].Votes != nil {
+		t.Error("expected no counts before voting")
+	}
+	voted := Chirp{}
+	optionId := chirp.Poll.Options[0].Id
+	if status := doRequest(t, http.MethodPost, voteURL, voterToken, VoteRequest{OptionId: optionId}, &voted); status != http.StatusOK {
+		t.Fatalf("vote: expected 200, got %d", status)
+	}
+	if poll := voted.Poll; poll.TotalVotes == nil || *poll.TotalVotes != 1 || *poll.VotedOptionId != optionId {
+		t.Errorf("expected the voter to see one vote for their option, got %+v", poll)
+	}
+	if status := doRequest(t, http.MethodPost, voteURL, voterToken, VoteRequest{OptionId: chirp.Poll.Options[1].Id}, nil); status != http.StatusConflict {
+		t.Errorf("voting twice: expected 409, got %d", status)
+	}
+	for _, token := range []string{"", otherToken} {
+		if poll := getPoll(t, ts.URL, token, chirp.Id); poll.TotalVotes != nil {
+			t.Error("expected no counts for viewers who haven't voted")
+		}
+	}
+
+	_, err := s.Config.db.Exec("UPDATE polls SET closes_at = $1 WHERE chirp_id = $2", time.Now().UTC().Add(-time.Second), chirp.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	poll := getPoll(t, ts.URL, "", chirp.Id)
+	if !poll.Closed || poll.TotalVotes == nil || *poll.TotalVotes != 1 {
+		t.Errorf("expected everyone to see the results of a closed poll, got %+v", poll)
+	}
+	if status := doRequest(t, http.MethodPost, voteURL, otherToken, VoteRequest{OptionId: optionId}, nil); status != http.StatusBadRequest {
+		t.Errorf("voting on a closed poll: expected 400, got %d", status)
+	}
+}
@@ -0,0 +1,461 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/chirptext"
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type ScheduledChirp struct {
+	Id             uuid.UUID   `json:"id"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+	Body           string      `json:"body"`
+	UserId         uuid.UUID   `json:"user_id"`
+	ReplyToId      *uuid.UUID  `json:"reply_to_id,omitempty"`
+	Visibility     string      `json:"visibility"`
+	MediaIds       []uuid.UUID `json:"media_ids"`
+	Status         string      `json:"status"`
+	PublishAt      *time.Time  `json:"publish_at,omitempty"`
+	FailureReason  string      `json:"failure_reason,omitempty"`
+	ContentWarning string      `json:"content_warning,omitempty"`
+	Sensitive      bool        `json:"sensitive"`
+}
+
+const (
+	scheduledStatusScheduled = "scheduled"
+	scheduledStatusDraft     = "draft"
+	// Chirps that can't be published when they're due, e.g. because the
+	// reply target was deleted, are kept as failed until edited or cancelled.
+	scheduledStatusFailed = "failed"
+	schedulerInterval     = 15 * time.Second
+)
+
+var scheduledStatuses = map[string]bool{
+	scheduledStatusScheduled: true,
+	scheduledStatusDraft:     true,
+	scheduledStatusFailed:    true,
+}
+
+// scheduledState works out the status and publish time of a scheduled chirp
+// or draft. The error string is empty when the request is valid.
+func scheduledState(reqChirp ChirpRequest) (string, sql.NullTime, string) {
+	if reqChirp.Draft {
+		if reqChirp.PublishAt != nil {
+			return "", sql.NullTime{}, "A draft can't have a publish_at"
+		}
+		return scheduledStatusDraft, sql.NullTime{}, ""
+	}
+	if reqChirp.PublishAt == nil {
+		return "", sql.NullTime{}, "publish_at is required"
+	}
+	if !reqChirp.PublishAt.After(time.Now()) {
+		return "", sql.NullTime{}, "publish_at must be in the future"
+	}
+	return scheduledStatusScheduled, sql.NullTime{Time: reqChirp.PublishAt.UTC(), Valid: true}, ""
+}
+
+// scheduleChirp stores a validated chirp request as a scheduled chirp or draft
+// instead of publishing it.
+func (cfg *apiConfig) scheduleChirp(w http.ResponseWriter, req *http.Request, dbUser database.User, reqChirp ChirpRequest, body, visibility string) {
+	status, publishAt, errorString := scheduledState(reqChirp)
+	if errorString != "" {
+		returnErrorResponse(w, errorString)
+		return
+	}
+	params := database.CreateScheduledChirpParams{
+		ID:             uuid.New(),
+		CreatedAt:      time.Now().UTC(),
+		UpdatedAt:      time.Now().UTC(),
+		UserID:         dbUser.ID,
+		Body:           body,
+		Visibility:     visibility,
+		MediaIds:       append([]uuid.UUID{}, reqChirp.MediaIds...),
+		Status:         status,
+		PublishAt:      publishAt,
+		ContentWarning: reqChirp.ContentWarning,
+		Sensitive:      reqChirp.Sensitive,
+	}
+	if reqChirp.ReplyToId != nil {
+		params.ReplyToID = uuid.NullUUID{UUID: *reqChirp.ReplyToId, Valid: true}
+	}
+	scheduled, err := cfg.dbQueries.CreateScheduledChirp(req.Context(), params)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(dbScheduledChirpToResponse(scheduled))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleGetScheduledChirps(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	params := database.GetScheduledChirpsForUserParams{
+		UserID: dbUser.ID,
+	}
+	if status := req.URL.Query().Get("status"); status != "" {
+		if !scheduledStatuses[status] {
+			returnErrorResponse(w, "Unknown status")
+			return
+		}
+		params.Status = sql.NullString{String: status, Valid: true}
+	}
+	var err error
+	params.MaxResults, err = queryLimit(req, defaultListLimit, maxListLimit)
+	if err != nil {
+		returnErrorResponse(w, "Invalid limit")
+		return
+	}
+	params.Skip, err = queryOffset(req)
+	if err != nil {
+		returnErrorResponse(w, "Invalid offset")
+		return
+	}
+
+	dbScheduled, err := cfg.dbQueries.GetScheduledChirpsForUser(req.Context(), params)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	response := []ScheduledChirp{}
+	for _, s := range dbScheduled {
+		response = append(response, dbScheduledChirpToResponse(s))
+	}
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleGetScheduledChirp(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	scheduled, ok := cfg.getScheduledChirp(w, req, dbUser.ID)
+	if !ok {
+		return
+	}
+
+	respBody, _ := encodeJson(dbScheduledChirpToResponse(scheduled))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// handleUpdateScheduledChirp replaces a scheduled chirp or draft. The body is
+// the same as POST /api/chirps, so a draft is scheduled by sending a
+// publish_at and a scheduled chirp goes back to being a draft with "draft".
+func (cfg *apiConfig) handleUpdateScheduledChirp(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	existing, ok := cfg.getScheduledChirp(w, req, dbUser.ID)
+	if !ok {
+		return
+	}
+
+	reqChirp := ChirpRequest{}
+	isValid, errorString := validateChirpRequest(req.Body, &reqChirp, cfg.entitlements(dbUser).MaxChirpLength)
+	if errorString != "" || !isValid {
+		returnErrorResponse(w, errorString)
+		return
+	}
+	visibility, _, ok := cfg.checkChirpRequest(w, req, dbUser, reqChirp)
+	if !ok {
+		return
+	}
+	moderated, ok := cfg.filterChirpBody(w, reqChirp.Body)
+	if !ok {
+		return
+	}
+	reqChirp.ContentWarning, ok = cfg.filterContentWarning(w, reqChirp.ContentWarning)
+	if !ok {
+		return
+	}
+	status, publishAt, errorString := scheduledState(reqChirp)
+	if errorString != "" {
+		returnErrorResponse(w, errorString)
+		return
+	}
+
+	params := database.UpdateScheduledChirpParams{
+		ID:             existing.ID,
+		UserID:         dbUser.ID,
+		Body:           moderated.Text,
+		Visibility:     visibility,
+		MediaIds:       append([]uuid.UUID{}, reqChirp.MediaIds...),
+		Status:         status,
+		PublishAt:      publishAt,
+		UpdatedAt:      time.Now().UTC(),
+		ContentWarning: reqChirp.ContentWarning,
+		Sensitive:      reqChirp.Sensitive,
+	}
+	if reqChirp.ReplyToId != nil {
+		params.ReplyToID = uuid.NullUUID{UUID: *reqChirp.ReplyToId, Valid: true}
+	}
+	updated, err := cfg.dbQueries.UpdateScheduledChirp(req.Context(), params)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Published while the request was in flight.
+		returnNotFound(w)
+		return
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(dbScheduledChirpToResponse(updated))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleDeleteScheduledChirp(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	scheduledId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+	deleted, err := cfg.dbQueries.DeleteScheduledChirp(req.Context(), database.DeleteScheduledChirpParams{
+		ID:     scheduledId,
+		UserID: dbUser.ID,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if deleted == 0 {
+		returnNotFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getScheduledChirp loads the caller's scheduled chirp or draft from the path,
+// writing a 404 for anyone else's.
+func (cfg *apiConfig) getScheduledChirp(w http.ResponseWriter, req *http.Request, userId uuid.UUID) (database.ScheduledChirp, bool) {
+	scheduledId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnNotFound(w)
+		return database.ScheduledChirp{}, false
+	}
+	scheduled, err := cfg.dbQueries.GetScheduledChirp(req.Context(), database.GetScheduledChirpParams{
+		ID:     scheduledId,
+		UserID: userId,
+	})
+	if err != nil {
+		returnNotFound(w)
+		return database.ScheduledChirp{}, false
+	}
+	return scheduled, true
+}
+
+func dbScheduledChirpToResponse(s database.ScheduledChirp) ScheduledChirp {
+	response := ScheduledChirp{
+		Id:             s.ID,
+		CreatedAt:      s.CreatedAt,
+		UpdatedAt:      s.UpdatedAt,
+		Body:           s.Body,
+		UserId:         s.UserID,
+		Visibility:     s.Visibility,
+		MediaIds:       s.MediaIds,
+		Status:         s.Status,
+		FailureReason:  s.FailureReason,
+		ContentWarning: s.ContentWarning,
+		Sensitive:      s.Sensitive,
+	}
+	if response.MediaIds == nil {
+		response.MediaIds = []uuid.UUID{}
+	}
+	if s.ReplyToID.Valid {
+		response.ReplyToId = &s.ReplyToID.UUID
+	}
+	if s.PublishAt.Valid {
+		response.PublishAt = &s.PublishAt.Time
+	}
+	return response
+}
+
+func (cfg *apiConfig) runScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			processed, err := cfg.publishDueChirps(ctx)
+			if err != nil {
+				log.Printf("error publishing scheduled chirps: %s\n", err)
+				continue
+			}
+			if processed > 0 {
+				log.Printf("processed %d scheduled chirps\n", processed)
+			}
+		}
+	}
+}
+
+// publishDueChirps publishes scheduled chirps one at a time until none are due.
+func (cfg *apiConfig) publishDueChirps(ctx context.Context) (int, error) {
+	processed := 0
+	for {
+		claimed, err := cfg.publishNextScheduledChirp(ctx)
+		if err != nil || !claimed {
+			return processed, err
+		}
+		processed++
+	}
+}
+
+// publishNextScheduledChirp claims one due chirp and publishes it. The row is
+// locked with SKIP LOCKED and deleted in the transaction that creates the
+// chirp, so with several instances running each chirp is published once.
+// Webhook and stream events are queued in the same transaction.
+func (cfg *apiConfig) publishNextScheduledChirp(ctx context.Context) (bool, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	scheduled, err := qtx.ClaimDueScheduledChirp(ctx, time.Now().UTC())
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	dbUser, err := qtx.GetUserById(ctx, scheduled.UserID)
+	if err != nil {
+		return false, err
+	}
+	reason, replyTo := cfg.checkScheduledChirp(ctx, qtx, dbUser, scheduled)
+	if reason != "" {
+		err = qtx.FailScheduledChirp(ctx, database.FailScheduledChirpParams{
+			ID:            scheduled.ID,
+			FailureReason: reason,
+			UpdatedAt:     time.Now().UTC(),
+		})
+		if err != nil {
+			return false, err
+		}
+		return true, tx.Commit()
+	}
+
+	// The filter may have changed since the chirp was scheduled.
+	moderated := cfg.profanity.Check(scheduled.Body)
+	chirp := Chirp{
+		Id:             uuid.New(),
+		CreatedAt:      time.Now().UTC(),
+		UpdatedAt:      time.Now().UTC(),
+		Body:           moderated.Text,
+		UserId:         scheduled.UserID,
+		Visibility:     scheduled.Visibility,
+		ContentWarning: cfg.profanity.Check(scheduled.ContentWarning).Text,
+		Sensitive:      scheduled.Sensitive,
+	}
+	params := database.CreateChirpParams{
+		ID:             chirp.Id,
+		CreatedAt:      chirp.CreatedAt,
+		UpdatedAt:      chirp.UpdatedAt,
+		Body:           chirp.Body,
+		UserID:         chirp.UserId,
+		Visibility:     chirp.Visibility,
+		ContentWarning: chirp.ContentWarning,
+		Sensitive:      chirp.Sensitive,
+		LinkUrl:        previewLink(chirp.Body),
+	}
+	if replyTo != nil {
+		chirp.ReplyToId = &replyTo.ID
+		params.ReplyToID = uuid.NullUUID{UUID: replyTo.ID, Valid: true}
+	}
+	_, err = qtx.CreateChirp(ctx, params)
+	if err != nil {
+		return false, err
+	}
+	err = queueLinkPreview(ctx, qtx, params.LinkUrl)
+	if err != nil {
+		return false, err
+	}
+	chirp.Media, err = attachMedia(ctx, qtx, chirp.Id, chirp.UserId, scheduled.MediaIds)
+	if err != nil {
+		return false, err
+	}
+	err = enqueueWebhookEvent(ctx, qtx, eventChirpCreated, chirp.UserId, chirp)
+	if err != nil {
+		return false, err
+	}
+	err = recordStreamEvent(ctx, qtx, eventChirpCreated, chirp)
+	if err != nil {
+		return false, err
+	}
+	err = qtx.DeletePublishedScheduledChirp(ctx, scheduled.ID)
+	if err != nil {
+		return false, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	cfg.flagIfNeeded(ctx, chirp.Id, moderated)
+	cfg.notifyChirpAudience(ctx, chirp, replyTo)
+	return true, nil
+}
+
+// checkScheduledChirp repeats the checks made when the chirp was scheduled,
+// since the author, the reply target or the uploads may have changed since.
+// It returns why the chirp can't be published, or "" and the reply target.
+func (cfg *apiConfig) checkScheduledChirp(ctx context.Context, q *database.Queries, dbUser database.User, scheduled database.ScheduledChirp) (string, *database.Chirp) {
+	if isAccountDisabled(dbUser) {
+		return "Account is disabled", nil
+	}
+	if chirptext.Length(scheduled.Body) > cfg.entitlements(dbUser).MaxChirpLength {
+		return "Chirp is too long", nil
+	}
+	if cfg.profanity.Check(scheduled.Body).Rejected || cfg.profanity.Check(scheduled.ContentWarning).Rejected {
+		return "Chirp contains prohibited language", nil
+	}
+	err := checkMediaIds(ctx, q, dbUser.ID, scheduled.MediaIds)
+	if err != nil {
+		return err.Error(), nil
+	}
+	blocked, err := mentionsBlocker(ctx, q, dbUser.ID, scheduled.Body)
+	if err != nil || blocked {
+		return errMentionsBlocker, nil
+	}
+	if !scheduled.ReplyToID.Valid {
+		return "", nil
+	}
+	parent, err := q.GetPublicChirpById(ctx, database.GetPublicChirpByIdParams{
+		ID:       scheduled.ReplyToID.UUID,
+		ViewerID: dbUser.ID,
+	})
+	if err != nil {
+		return "Reply target not found", nil
+	}
+	return "", &parent
+}
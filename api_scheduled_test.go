@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScheduledState(t *testing.T) {
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	cases := []struct {
+		name    string
+		request ChirpRequest
+		status  string
+	}{
+		{"draft", ChirpRequest{Draft: true}, scheduledStatusDraft},
+		{"draft with publish_at", ChirpRequest{Draft: true, PublishAt: &future}, ""},
+		{"scheduled", ChirpRequest{PublishAt: &future}, scheduledStatusScheduled},
+		{"scheduled in the past", ChirpRequest{PublishAt: &past}, ""},
+		{"neither", ChirpRequest{}, ""},
+	}
+	for _, c := range cases {
+		status, publishAt, errorString := scheduledState(c.request)
+		if status != c.status || (errorString == "") != (c.status != "") {
+			t.Errorf("%s: got status %q, error %q", c.name, status, errorString)
+		}
+		if publishAt.Valid != (status == scheduledStatusScheduled) {
+			t.Errorf("%s: expected publish_at only when scheduled, got %v", c.name, publishAt)
+		}
+	}
+}
+
+// scheduleTestChirp schedules chirp through the API and makes it due now.
+func scheduleTestChirp(t *testing.T, s *Server, url, token string, chirp ChirpRequest) ScheduledChirp {
+	t.Helper()
+	scheduled := ScheduledChirp{}
+	status := doRequest(t, http.MethodPost, url+"/api/chirps", token, chirp, &scheduled)
+	if status != http.StatusCreated {
+		t.Fatalf("scheduling: expected 201, got %d", status)
+	}
+	_, err := s.Config.db.Exec("UPDATE scheduled_chirps SET publish_at = $1 WHERE id = $2", time.Now().UTC().Add(-time.Second), scheduled.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scheduled
+}
+
+func TestScheduledChirpsPublishOnce(t *testing.T) {
+	s, ts := testDBServer(t)
+	author, token := createTestUser(t, s)
+	publishAt := time.Now().Add(time.Hour)
+	scheduleTestChirp(t, s, ts.URL, token, ChirpRequest{Body: "queued", PublishAt: &publishAt})
+	draft := ScheduledChirp{}
+	doRequest(t, http.MethodPost, ts.URL+"/api/chirps", token, ChirpRequest{Body: "draft", Draft: true}, &draft)
+
+	// Several instances running the scheduler at the same moment.
+	published := make([]int, 4)
+	wg := sync.WaitGroup{}
+	for i := range published {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			published[i], _ = s.Config.publishDueChirps(context.Background())
+		}()
+	}
+	wg.Wait()
+	total := 0
+	for _, n := range published {
+		total += n
+	}
+	if total != 1 {
+		t.Errorf("expected the chirp to be published once, got %d", total)
+	}
+
+	feed := AuthorFeed{}
+	doRequest(t, http.MethodGet, ts.URL+"/api/chirps?author_id="+author.ID.String(), "", nil, &feed)
+	if len(feed.Chirps) != 1 || feed.Chirps[0].Body != "queued" {
+		t.Errorf("expected only the scheduled chirp to be published, got %v", feed.Chirps)
+	}
+	remaining := []ScheduledChirp{}
+	doRequest(t, http.MethodGet, ts.URL+"/api/scheduled-chirps", token, nil, &remaining)
+	if len(remaining) != 1 || remaining[0].Id != draft.Id {
+		t.Errorf("expected only the draft to remain, got %v", remaining)
+	}
+}
+
+func TestScheduledChirpFailuresAreRecorded(t *testing.T) {
+	s, ts := testDBServer(t)
+	author, token := createTestUser(t, s)
+	parent := postChirp(t, ts, token, ChirpRequest{Body: "parent"})
+	publishAt := time.Now().Add(time.Hour)
+	scheduled := scheduleTestChirp(t, s, ts.URL, token, ChirpRequest{Body: "reply", ReplyToId: &parent.Id, PublishAt: &publishAt})
+	if status := doRequest(t, http.MethodDelete, ts.URL+"/api/chirps/"+parent.Id.String(), token, nil, nil); status != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d", status)
+	}
+
+	processed, err := s.Config.publishDueChirps(context.Background())
+	if err != nil || processed != 1 {
+		t.Fatalf("expected one chirp processed, got %d, %v", processed, err)
+	}
+	failed := ScheduledChirp{}
+	doRequest(t, http.MethodGet, ts.URL+"/api/scheduled-chirps/"+scheduled.Id.String(), token, nil, &failed)
+	if failed.Status != scheduledStatusFailed || failed.FailureReason != "Reply target not found" {
+		t.Errorf("expected a failure for the missing reply target, got %q %q", failed.Status, failed.FailureReason)
+	}
+	feed := AuthorFeed{}
+	doRequest(t, http.MethodGet, ts.URL+"/api/chirps?author_id="+author.ID.String(), "", nil, &feed)
+	if len(feed.Chirps) != 0 {
+		t.Errorf("expected nothing published, got %v", feed.Chirps)
+	}
+
+	// Failed chirps aren't retried until they're edited.
+	if processed, _ := s.Config.publishDueChirps(context.Background()); processed != 0 {
+		t.Errorf("expected the failed chirp to be left alone, got %d processed", processed)
+	}
+}
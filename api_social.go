@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/chirptext"
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handleFollowUser(w http.ResponseWriter, req *http.Request) {
+	dbUser, followeeId, ok := cfg.authenticateUserAction(w, req)
+	if !ok {
+		return
+	}
+	blocked, err := cfg.dbQueries.IsBlockedEitherWay(req.Context(), database.IsBlockedEitherWayParams{
+		UserID:  dbUser.ID,
+		OtherID: followeeId,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if blocked {
+		returnForbidden(w)
+		return
+	}
+
+	followed, err := cfg.dbQueries.FollowUser(req.Context(), database.FollowUserParams{
+		FollowerID: dbUser.ID,
+		FolloweeID: followeeId,
+		CreatedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if followed > 0 {
+		cfg.notify(req.Context(), followeeId, dbUser.ID, notificationFollow, uuid.NullUUID{})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUnfollowUser(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	followeeId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	_, err = cfg.dbQueries.UnfollowUser(req.Context(), database.UnfollowUserParams{
+		FollowerID: dbUser.ID,
+		FolloweeID: followeeId,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleLikeChirp(w http.ResponseWriter, req *http.Request) {
+	dbUser, dbChirp, ok := cfg.authenticateChirpAction(w, req)
+	if !ok {
+		return
+	}
+
+	liked, err := cfg.dbQueries.LikeChirp(req.Context(), database.LikeChirpParams{
+		UserID:    dbUser.ID,
+		ChirpID:   dbChirp.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if liked > 0 {
+		cfg.notify(req.Context(), dbChirp.UserID, dbUser.ID, notificationLike, uuid.NullUUID{UUID: dbChirp.ID, Valid: true})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUnlikeChirp(w http.ResponseWriter, req *http.Request) {
+	dbUser, dbChirp, ok := cfg.authenticateChirpAction(w, req)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.dbQueries.UnlikeChirp(req.Context(), database.UnlikeChirpParams{
+		UserID:  dbUser.ID,
+		ChirpID: dbChirp.ID,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleRechirp(w http.ResponseWriter, req *http.Request) {
+	dbUser, dbChirp, ok := cfg.authenticateChirpAction(w, req)
+	if !ok {
+		return
+	}
+	// Rechirping would show the chirp to people its author didn't share it with.
+	if dbChirp.Visibility != visibilityPublic && dbChirp.Visibility != visibilityUnlisted {
+		returnForbidden(w)
+		return
+	}
+
+	rechirped, err := cfg.dbQueries.Rechirp(req.Context(), database.RechirpParams{
+		UserID:    dbUser.ID,
+		ChirpID:   dbChirp.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if rechirped > 0 {
+		cfg.notify(req.Context(), dbChirp.UserID, dbUser.ID, notificationRechirp, uuid.NullUUID{UUID: dbChirp.ID, Valid: true})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUndoRechirp(w http.ResponseWriter, req *http.Request) {
+	dbUser, dbChirp, ok := cfg.authenticateChirpAction(w, req)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.dbQueries.UndoRechirp(req.Context(), database.UndoRechirpParams{
+		UserID:  dbUser.ID,
+		ChirpID: dbChirp.ID,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authenticateChirpAction loads the caller and the public chirp in the path,
+// writing the error response when either is missing.
+func (cfg *apiConfig) authenticateChirpAction(w http.ResponseWriter, req *http.Request) (database.User, database.Chirp, bool) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return database.User{}, database.Chirp{}, false
+	}
+	chirpId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return database.User{}, database.Chirp{}, false
+	}
+	dbChirp, err := cfg.dbQueries.GetPublicChirpById(req.Context(), database.GetPublicChirpByIdParams{
+		ID:       chirpId,
+		ViewerID: dbUser.ID,
+	})
+	if err != nil {
+		returnNotFound(w)
+		return database.User{}, database.Chirp{}, false
+	}
+	return dbUser, dbChirp, true
+}
+
+// notifyChirpAudience tells the author of the chirp being replied to and
+// every mentioned user about a new chirp, as long as its visibility lets them
+// read it.
+func (cfg *apiConfig) notifyChirpAudience(ctx context.Context, chirp Chirp, replyTo *database.Chirp) {
+	replyToAuthor := uuid.Nil
+	if replyTo != nil {
+		replyToAuthor = replyTo.UserID
+	}
+	mentioned := []uuid.UUID{}
+	if emails := chirptext.Mentions(chirp.Body); len(emails) > 0 {
+		dbUsers, err := cfg.dbQueries.GetUsersByEmails(ctx, emails)
+		if err != nil {
+			log.Printf("error loading mentioned users: %s\n", err)
+		}
+		for _, u := range dbUsers {
+			mentioned = append(mentioned, u.ID)
+		}
+	}
+
+	chirpId := uuid.NullUUID{UUID: chirp.Id, Valid: true}
+	for _, notice := range chirpAudience(chirp.UserId, replyToAuthor, mentioned) {
+		if cfg.canViewChirp(ctx, chirp.Id, notice.userId) {
+			cfg.notify(ctx, notice.userId, chirp.UserId, notice.notificationType, chirpId)
+		}
+	}
+}
+
+type chirpNotice struct {
+	userId           uuid.UUID
+	notificationType string
+}
+
+// chirpAudience picks who hears about a new chirp: the author of the chirp it
+// replies to (uuid.Nil for none), then the mentioned users. Each user is told
+// once, so one who is both only gets the reply notification, and the author
+// isn't told about their own chirp.
+func chirpAudience(authorId, replyToAuthor uuid.UUID, mentioned []uuid.UUID) []chirpNotice {
+	notices := []chirpNotice{}
+	notified := map[uuid.UUID]bool{authorId: true}
+	if replyToAuthor != uuid.Nil && !notified[replyToAuthor] {
+		notified[replyToAuthor] = true
+		notices = append(notices, chirpNotice{userId: replyToAuthor, notificationType: notificationReply})
+	}
+	for _, userId := range mentioned {
+		if notified[userId] {
+			continue
+		}
+		notified[userId] = true
+		notices = append(notices, chirpNotice{userId: userId, notificationType: notificationMention})
+	}
+	return notices
+}
+
+func (cfg *apiConfig) canViewChirp(ctx context.Context, chirpId, viewerId uuid.UUID) bool {
+	_, err := cfg.dbQueries.GetPublicChirpById(ctx, database.GetPublicChirpByIdParams{
+		ID:       chirpId,
+		ViewerID: viewerId,
+	})
+	return err == nil
+}
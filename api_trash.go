@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTrashRetention = 30 * 24 * time.Hour
+	trashPurgeInterval    = time.Hour
+	trashPurgeBatch       = 500
+)
+
+// handleGetTrash lists the caller's deleted chirps that can still be restored,
+// most recently deleted first.
+func (cfg *apiConfig) handleGetTrash(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	params := database.GetTrashedChirpsParams{
+		UserID:       dbUser.ID,
+		DeletedAfter: time.Now().UTC().Add(-cfg.TrashRetention),
+	}
+	var err error
+	params.MaxResults, err = queryLimit(req, defaultListLimit, maxListLimit)
+	if err != nil {
+		returnErrorResponse(w, "Invalid limit")
+		return
+	}
+	params.Skip, err = queryOffset(req)
+	if err != nil {
+		returnErrorResponse(w, "Invalid offset")
+		return
+	}
+
+	dbChirps, err := cfg.dbQueries.GetTrashedChirps(req.Context(), params)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	cfg.returnChirpsResponse(w, req, dbChirps, dbUser.ID)
+}
+
+func (cfg *apiConfig) handleRestoreChirp(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	chirpId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+	dbChirp, err := cfg.dbQueries.GetAnyChirpById(req.Context(), chirpId)
+	if err != nil || dbChirp.UserID != dbUser.ID {
+		returnNotFound(w)
+		return
+	}
+	if !dbChirp.DeletedAt.Valid {
+		returnErrorResponse(w, "Chirp is not in the trash")
+		return
+	}
+
+	restored, err := cfg.dbQueries.RestoreChirp(req.Context(), database.RestoreChirpParams{
+		ID:           dbChirp.ID,
+		UserID:       dbUser.ID,
+		DeletedAfter: time.Now().UTC().Add(-cfg.TrashRetention),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Past the retention window, waiting to be purged, or removed by a
+		// moderator.
+		returnNotFound(w)
+		return
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	// Events are built for an anonymous viewer so they don't carry the
+	// author's own poll vote.
+	event := []Chirp{dbChirpToResponse(restored)}
+	chirps := []Chirp{dbChirpToResponse(restored)}
+	err = cfg.loadChirpDetails(req.Context(), event, uuid.Nil)
+	if err == nil {
+		err = cfg.loadChirpDetails(req.Context(), chirps, dbUser.ID)
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	cfg.publishWebhookEvent(req.Context(), eventChirpRestored, restored.UserID, event[0])
+	cfg.publishStreamEvent(req.Context(), eventChirpRestored, event[0])
+
+	respBody, _ := encodeJson(chirps[0])
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// runTrashPurge permanently deletes chirps that have been in the trash for
+// longer than the retention window. Likes, rechirps and notifications go with
+// them; their media is left to the orphaned media cleanup.
+func (cfg *apiConfig) runTrashPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := cfg.purgeTrash(ctx)
+			if err != nil {
+				log.Printf("error purging deleted chirps: %s\n", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("purged %d deleted chirps\n", purged)
+			}
+		}
+	}
+}
+
+func (cfg *apiConfig) purgeTrash(ctx context.Context) (int64, error) {
+	var purged int64
+	for {
+		n, err := cfg.dbQueries.PurgeDeletedChirps(ctx, database.PurgeDeletedChirpsParams{
+			DeletedBefore: time.Now().UTC().Add(-cfg.TrashRetention),
+			MaxChirps:     trashPurgeBatch,
+		})
+		purged += n
+		if err != nil || n < trashPurgeBatch {
+			return purged, err
+		}
+	}
+}
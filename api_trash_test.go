@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+// trashFor returns the chirps in the trash of token's user.
+func trashFor(t *testing.T, url, token string) []Chirp {
+	t.Helper()
+	trash := []Chirp{}
+	status := doRequest(t, http.MethodGet, url+"/api/users/me/trash", token, nil, &trash)
+	if status != http.StatusOK {
+		t.Fatalf("GET /api/users/me/trash: expected 200, got %d", status)
+	}
+	return trash
+}
+
+func TestDeletedChirpsCanBeRestored(t *testing.T) {
+	s, ts := testDBServer(t)
+	_, token := createTestUser(t, s)
+	_, otherToken := createTestUser(t, s)
+	chirp := postChirp(t, ts, token, ChirpRequest{Body: "oops"})
+	chirpURL := ts.URL + "/api/chirps/" + chirp.Id.String()
+
+	if status := doRequest(t, http.MethodDelete, chirpURL, token, nil, nil); status != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d", status)
+	}
+	if status := doRequest(t, http.MethodGet, chirpURL, token, nil, nil); status != http.StatusNotFound {
+		t.Errorf("reading a deleted chirp: expected 404, got %d", status)
+	}
+	if ids := chirpIds(trashFor(t, ts.URL, token)); !slices.Equal(ids, chirpIds([]Chirp{chirp})) {
+		t.Errorf("expected the chirp in the trash, got %v", ids)
+	}
+	if len(trashFor(t, ts.URL, otherToken)) != 0 {
+		t.Error("expected other users' trash to be empty")
+	}
+
+	if status := doRequest(t, http.MethodPost, chirpURL+"/restore", otherToken, nil, nil); status != http.StatusNotFound {
+		t.Errorf("restoring someone else's chirp: expected 404, got %d", status)
+	}
+	restored := Chirp{}
+	if status := doRequest(t, http.MethodPost, chirpURL+"/restore", token, nil, &restored); status != http.StatusOK {
+		t.Fatalf("restore: expected 200, got %d", status)
+	}
+	if restored.DeletedAt != nil {
+		t.Errorf("expected the restored chirp to have no deleted_at, got %v", restored.DeletedAt)
+	}
+	if status := doRequest(t, http.MethodGet, chirpURL, "", nil, nil); status != http.StatusOK {
+		t.Errorf("reading a restored chirp: expected 200, got %d", status)
+	}
+	if status := doRequest(t, http.MethodPost, chirpURL+"/restore", token, nil, nil); status != http.StatusBadRequest {
+		t.Errorf("restoring a chirp that isn't deleted: expected 400, got %d", status)
+	}
+}
+
+func TestExpiredTrashIsPurged(t *testing.T) {
+	s, ts := testDBServer(t)
+	_, token := createTestUser(t, s)
+	expired := postChirp(t, ts, token, ChirpRequest{Body: "old"})
+	recent := postChirp(t, ts, token, ChirpRequest{Body: "new"})
+	for _, chirp := range []Chirp{expired, recent} {
+		doRequest(t, http.MethodDelete, ts.URL+"/api/chirps/"+chirp.Id.String(), token, nil, nil)
+	}
+	deletedAt := time.Now().UTC().Add(-s.Config.TrashRetention - time.Minute)
+	_, err := s.Config.db.Exec("UPDATE chirps SET deleted_at = $1 WHERE id = $2", deletedAt, expired.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Out of the window, but not purged yet.
+	if ids := chirpIds(trashFor(t, ts.URL, token)); !slices.Equal(ids, chirpIds([]Chirp{recent})) {
+		t.Errorf("expected only the recent chirp in the trash, got %v", ids)
+	}
+	if status := doRequest(t, http.MethodPost, ts.URL+"/api/chirps/"+expired.Id.String()+"/restore", token, nil, nil); status != http.StatusNotFound {
+		t.Errorf("restoring an expired chirp: expected 404, got %d", status)
+	}
+
+	purged, err := s.Config.purgeTrash(context.Background())
+	if err != nil || purged != 1 {
+		t.Fatalf("expected one chirp purged, got %d, %v", purged, err)
+	}
+	if _, err := s.Config.dbQueries.GetAnyChirpById(context.Background(), expired.Id); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected the expired chirp to be gone, got %v", err)
+	}
+	if status := doRequest(t, http.MethodPost, ts.URL+"/api/chirps/"+recent.Id.String()+"/restore", token, nil, nil); status != http.StatusOK {
+		t.Errorf("restoring a recent chirp after a purge: expected 200, got %d", status)
+	}
+}
@@ -69,31 +69,21 @@ func (cfg *apiConfig) handleNewUser(w http.ResponseWriter, req *http.Request) {
 		Email:     dbUser.Email,
 	}
 
+	cfg.publishWebhookEvent(req.Context(), eventUserCreated, newUser.Id, newUser)
 	respBody, _ := encodeJson(newUser)
 	w.WriteHeader(http.StatusCreated)
 	w.Write(respBody)
 }
 
 func (cfg *apiConfig) handleUserUpdate(w http.ResponseWriter, req *http.Request) {
-	token, err := auth.GetBearerToken(req.Header)
-	if err != nil {
-		returnUnauthorized(w)
-		return
-	}
-
-	jwtId, err := auth.ValidateJWT(token, cfg.Secret)
-	if err != nil {
-		if err.Error() == "invalid token" || err.Error() == "subject is empty" {
-			returnBadRequest(w)
-		} else {
-			returnUnauthorized(w)
-		}
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
 		return
 	}
 
 	payload := UserPreferences{}
 	decoder := json.NewDecoder(req.Body)
-	err = decoder.Decode(&payload)
+	err := decoder.Decode(&payload)
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
@@ -105,13 +95,13 @@ func (cfg *apiConfig) handleUserUpdate(w http.ResponseWriter, req *http.Request)
 		return
 	}
 	params := database.UpdateUserParams{
-		ID:             jwtId,
+		ID:             dbUser.ID,
 		Email:          payload.Email,
 		UpdatedAt:      time.Now().UTC(),
 		HashedPassword: hash,
 	}
 
-	dbUser, err := cfg.dbQueries.UpdateUser(req.Context(), params)
+	dbUser, err = cfg.dbQueries.UpdateUser(req.Context(), params)
 	if err != nil {
 		returnErrorResponse(w, standardError)
 		return
@@ -123,7 +113,62 @@ func (cfg *apiConfig) handleUserUpdate(w http.ResponseWriter, req *http.Request)
 		UpdatedAt: dbUser.UpdatedAt,
 		Email:     dbUser.Email,
 	}
+	cfg.publishWebhookEvent(req.Context(), eventUserUpdated, updatedUser.Id, updatedUser)
 	respBody, _ := encodeJson(updatedUser)
 	w.WriteHeader(http.StatusOK)
 	w.Write(respBody)
 }
+
+type PrivacySettings struct {
+	Protected bool `json:"protected"`
+}
+
+func (cfg *apiConfig) handleGetPrivacySettings(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	respBody, _ := encodeJson(PrivacySettings{Protected: dbUser.IsProtected})
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// handleUpdatePrivacySettings toggles a protected account. It only changes the
+// default for new chirps; existing chirps keep their visibility.
+func (cfg *apiConfig) handleUpdatePrivacySettings(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	payload := PrivacySettings{}
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	dbUser, err = cfg.dbQueries.SetUserProtected(req.Context(), database.SetUserProtectedParams{
+		ID:          dbUser.ID,
+		IsProtected: payload.Protected,
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(PrivacySettings{Protected: dbUser.IsProtected})
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// isAccountDisabled reports whether the user is banned or currently suspended.
+func isAccountDisabled(u database.User) bool {
+	if u.BannedAt.Valid {
+		return true
+	}
+	return u.SuspendedUntil.Valid && u.SuspendedUntil.Time.After(time.Now().UTC())
+}
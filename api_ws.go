@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/auth"
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/aramirez3/chirpy/internal/events"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// SocketMessage is sent by clients over /api/ws.
+type SocketMessage struct {
+	Type     string `json:"type"`
+	Id       string `json:"id,omitempty"`
+	Topic    string `json:"topic,omitempty"`
+	AuthorId string `json:"author_id,omitempty"`
+	Hashtag  string `json:"hashtag,omitempty"`
+}
+
+// SocketReply is sent by the server: acks and errors echo the client's
+// message id, events carry the topic they were published on.
+type SocketReply struct {
+	Type    string          `json:"type"`
+	Id      string          `json:"id,omitempty"`
+	Topic   string          `json:"topic,omitempty"`
+	Event   string          `json:"event,omitempty"`
+	EventId int64           `json:"event_id,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// UserEvent is the NOTIFY payload for events addressed to a single user.
+// Events about a message carry its id instead of data, since a message can be
+// too big for a NOTIFY; the listener loads it.
+type UserEvent struct {
+	UserId    uuid.UUID       `json:"user_id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	MessageId *uuid.UUID      `json:"message_id,omitempty"`
+}
+
+const (
+	socketTopicChirps        = "chirps"
+	socketTopicNotifications = "notifications"
+	socketTopicMessages      = "messages"
+)
+
+const (
+	socketSubscribe   = "subscribe"
+	socketUnsubscribe = "unsubscribe"
+	socketPing        = "ping"
+	socketPong        = "pong"
+	socketAck         = "ack"
+	socketError       = "error"
+	socketEvent       = "event"
+)
+
+const (
+	userEventsChannel        = "chirpy_user_events"
+	defaultMaxSocketsPerUser = 5
+	socketSendBuffer         = 16
+	socketEventBuffer        = 64
+	socketMaxMessage         = 4096
+	socketWriteWait          = 10 * time.Second
+	socketPongWait           = 60 * time.Second
+	socketPingPeriod         = 50 * time.Second
+)
+
+var socketUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// checkSocketOrigin lets browsers open sockets from this server's own origin
+// and from SocketOrigins. Clients that aren't browsers send no Origin.
+func (cfg *apiConfig) checkSocketOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, req.Host) {
+		return true
+	}
+	for _, allowed := range cfg.SocketOrigins {
+		if strings.EqualFold(strings.TrimSuffix(strings.TrimSpace(allowed), "/"), u.Scheme+"://"+u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// socketRegistry counts open sockets per user to enforce the connection limit.
+// The counts live in memory, so the limit applies to each instance separately.
+type socketRegistry struct {
+	mu    sync.Mutex
+	conns map[uuid.UUID]int
+}
+
+type socketClient struct {
+	conn   *websocket.Conn
+	userId uuid.UUID
+	send   chan SocketReply
+	hidden map[uuid.UUID]bool
+	mu     sync.Mutex
+	topics map[string]streamFilter
+}
+
+func newSocketRegistry() *socketRegistry {
+	return &socketRegistry{conns: map[uuid.UUID]int{}}
+}
+
+func (r *socketRegistry) acquire(userId uuid.UUID, max int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conns[userId] >= max {
+		return false
+	}
+	r.conns[userId]++
+	return true
+}
+
+func (r *socketRegistry) release(userId uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conns[userId]--
+	if r.conns[userId] <= 0 {
+		delete(r.conns, userId)
+	}
+}
+
+// recordUserEvent NOTIFYs every instance about an event for one user, so it
+// reaches their sockets wherever they are connected.
+func recordUserEvent(ctx context.Context, q *database.Queries, userId uuid.UUID, eventType string, data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(UserEvent{
+		UserId: userId,
+		Type:   eventType,
+		Data:   encoded,
+	})
+	if err != nil {
+		return err
+	}
+	return q.NotifyChannel(ctx, database.NotifyChannelParams{
+		Channel: userEventsChannel,
+		Payload: string(payload),
+	})
+}
+
+func (cfg *apiConfig) publishUserEvent(ctx context.Context, userId uuid.UUID, eventType string, data any) {
+	err := recordUserEvent(ctx, cfg.dbQueries, userId, eventType, data)
+	if err != nil {
+		log.Printf("error publishing %s user event: %s\n", eventType, err)
+	}
+}
+
+// publishMessageCreated tells the recipient about a new message. It's sent
+// after the message is committed so the listener can load it.
+func (cfg *apiConfig) publishMessageCreated(ctx context.Context, userId, messageId uuid.UUID) {
+	payload, err := json.Marshal(UserEvent{
+		UserId:    userId,
+		Type:      eventMessageCreated,
+		MessageId: &messageId,
+	})
+	if err == nil {
+		err = cfg.dbQueries.NotifyChannel(ctx, database.NotifyChannelParams{
+			Channel: userEventsChannel,
+			Payload: string(payload),
+		})
+	}
+	if err != nil {
+		log.Printf("error publishing %s user event: %s\n", eventMessageCreated, err)
+	}
+}
+
+// userEventTopic picks the socket topic a user event is delivered on.
+func userEventTopic(eventType string) string {
+	if strings.HasPrefix(eventType, "message.") {
+		return socketTopicMessages
+	}
+	return socketTopicNotifications
+}
+
+// loadUserEvent is the events.Loader for user events, which travel whole in
+// the NOTIFY payload apart from messages.
+func (cfg *apiConfig) loadUserEvent(ctx context.Context, payload string) (events.Event, error) {
+	userEvent := UserEvent{}
+	err := json.Unmarshal([]byte(payload), &userEvent)
+	if err != nil {
+		return events.Event{}, err
+	}
+	if userEvent.MessageId != nil {
+		dbMessage, err := cfg.dbQueries.GetMessageById(ctx, *userEvent.MessageId)
+		if err != nil {
+			return events.Event{}, err
+		}
+		userEvent.Data, err = json.Marshal(dbMessageToResponse(dbMessage))
+		if err != nil {
+			return events.Event{}, err
+		}
+	}
+	return events.Event{
+		Key:  userEvent.UserId.String(),
+		Type: userEvent.Type,
+		Data: userEvent.Data,
+	}, nil
+}
+
+// handleSocket upgrades to a WebSocket. Browsers can't set headers on socket
+// requests, so the JWT may also be passed as ?access_token=.
+func (cfg *apiConfig) handleSocket(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		token = req.URL.Query().Get("access_token")
+	}
+	dbUser, ok := cfg.authenticateToken(w, req, token)
+	if !ok {
+		return
+	}
+	if !cfg.sockets.acquire(dbUser.ID, cfg.MaxSocketsPerUser) {
+		returnTooManyRequests(w)
+		return
+	}
+	defer cfg.sockets.release(dbUser.ID)
+
+	// Blocks and mutes are read once; changes apply from the next connection.
+	hiddenIds, err := cfg.dbQueries.GetHiddenAuthorIds(req.Context(), dbUser.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	hidden := map[uuid.UUID]bool{}
+	for _, id := range hiddenIds {
+		hidden[id] = true
+	}
+
+	upgrader := socketUpgrader
+	upgrader.CheckOrigin = cfg.checkSocketOrigin
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	client := &socketClient{
+		conn:   conn,
+		userId: dbUser.ID,
+		send:   make(chan SocketReply, socketSendBuffer),
+		hidden: hidden,
+		topics: map[string]streamFilter{},
+	}
+
+	chirps := cfg.eventBus.Subscribe(socketEventBuffer)
+	defer chirps.Unsubscribe()
+	userEvents := cfg.userEventBus.Subscribe(socketEventBuffer)
+	defer userEvents.Unsubscribe()
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		client.writePump(chirps.C, userEvents.C, done)
+		close(finished)
+	}()
+	client.readPump()
+	close(done)
+	<-finished
+}
+
+func (c *socketClient) readPump() {
+	c.conn.SetReadLimit(socketMaxMessage)
+	c.conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		msg := SocketMessage{}
+		reply := SocketReply{Type: socketError, Error: standardError}
+		if json.Unmarshal(data, &msg) == nil {
+			reply = c.handleMessage(msg)
+		}
+		// A client that doesn't read its replies is as slow as one that
+		// doesn't read events.
+		select {
+		case c.send <- reply:
+		default:
+			return
+		}
+	}
+}
+
+func (c *socketClient) handleMessage(msg SocketMessage) SocketReply {
+	reply := SocketReply{Type: socketAck, Id: msg.Id, Topic: msg.Topic}
+	switch msg.Type {
+	case socketPing:
+		reply.Type = socketPong
+	case socketSubscribe:
+		if msg.Topic != socketTopicChirps && msg.Topic != socketTopicNotifications && msg.Topic != socketTopicMessages {
+			return SocketReply{Type: socketError, Id: msg.Id, Error: "Unknown topic"}
+		}
+		filter, err := newStreamFilter(msg.AuthorId, msg.Hashtag)
+		if err != nil {
+			return SocketReply{Type: socketError, Id: msg.Id, Error: err.Error()}
+		}
+		filter.hidden = c.hidden
+		c.mu.Lock()
+		c.topics[msg.Topic] = filter
+		c.mu.Unlock()
+	case socketUnsubscribe:
+		c.mu.Lock()
+		delete(c.topics, msg.Topic)
+		c.mu.Unlock()
+	default:
+		return SocketReply{Type: socketError, Id: msg.Id, Error: "Unknown message type"}
+	}
+	return reply
+}
+
+// writePump is the only goroutine writing to the connection. When the client
+// can't keep up, its bus subscription is dropped and the socket is closed
+// with "try again later".
+func (c *socketClient) writePump(chirps, userEvents <-chan events.Event, done <-chan struct{}) {
+	ping := time.NewTicker(socketPingPeriod)
+	defer ping.Stop()
+	defer c.conn.Close()
+
+	for {
+		var err error
+		select {
+		case <-done:
+			c.close(websocket.CloseNormalClosure, "")
+			return
+		case reply := <-c.send:
+			err = c.write(reply)
+		case event, ok := <-chirps:
+			if !ok {
+				c.close(websocket.CloseTryAgainLater, "slow consumer")
+				return
+			}
+			if c.wants(socketTopicChirps, event) {
+				err = c.writeEvent(socketTopicChirps, event)
+			}
+		case event, ok := <-userEvents:
+			if !ok {
+				c.close(websocket.CloseTryAgainLater, "slow consumer")
+				return
+			}
+			topic := userEventTopic(event.Type)
+			if event.Key == c.userId.String() && c.wants(topic, event) {
+				err = c.writeEvent(topic, event)
+			}
+		case <-ping.C:
+			err = c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteWait))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *socketClient) wants(topic string, event events.Event) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	filter, ok := c.topics[topic]
+	if !ok {
+		return false
+	}
+	return topic != socketTopicChirps || filter.matches(event)
+}
+
+func (c *socketClient) writeEvent(topic string, event events.Event) error {
+	return c.write(SocketReply{
+		Type:    socketEvent,
+		Topic:   topic,
+		Event:   event.Type,
+		EventId: event.ID,
+		Data:    event.Data,
+	})
+}
+
+func (c *socketClient) write(reply SocketReply) error {
+	c.conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+	return c.conn.WriteJSON(reply)
+}
+
+func (c *socketClient) close(code int, text string) {
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(socketWriteWait))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aramirez3/chirpy/internal/events"
+	"github.com/google/uuid"
+)
+
+func TestCheckSocketOrigin(t *testing.T) {
+	cfg := &createServer("0").Config
+	cfg.SocketOrigins = []string{"https://app.example.com/", " https://other.example.com"}
+	cases := map[string]bool{
+		"":                           true,
+		"http://chirpy.test":         true,
+		"https://app.example.com":    true,
+		"https://OTHER.example.com":  true,
+		"http://app.example.com":     false,
+		"https://evil.example.com":   false,
+		"https://chirpy.test.evil.x": false,
+	}
+	for origin, expected := range cases {
+		req := httptest.NewRequest("GET", "http://chirpy.test/api/ws", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if got := cfg.checkSocketOrigin(req); got != expected {
+			t.Errorf("checkSocketOrigin(%q) = %v, expected %v", origin, got, expected)
+		}
+	}
+}
+
+func TestCreateServerSetsUpSockets(t *testing.T) {
+	cfg := &createServer("0").Config
+	if cfg.sockets == nil || cfg.userEventBus == nil {
+		t.Fatal("expected createServer to set up the socket registry and user event bus")
+	}
+	userId := uuid.New()
+	if !cfg.sockets.acquire(userId, 1) || cfg.sockets.acquire(userId, 1) {
+		t.Error("expected the registry to enforce the connection limit")
+	}
+}
+
+func TestSubscribeToMessages(t *testing.T) {
+	client := &socketClient{userId: uuid.New(), topics: map[string]streamFilter{}}
+	reply := client.handleMessage(SocketMessage{Type: socketSubscribe, Id: "1", Topic: socketTopicMessages})
+	if reply.Type != socketAck {
+		t.Fatalf("expected an ack, got %+v", reply)
+	}
+	event := events.Event{Key: client.userId.String(), Type: "message.created", Data: []byte(`{}`)}
+	topic := userEventTopic(event.Type)
+	if topic != socketTopicMessages || !client.wants(topic, event) {
+		t.Errorf("expected message events to be delivered on the messages topic")
+	}
+
+	reply = client.handleMessage(SocketMessage{Type: socketSubscribe, Id: "2", Topic: "secrets"})
+	if reply.Type != socketError {
+		t.Errorf("expected unknown topics to be refused, got %+v", reply)
+	}
+}
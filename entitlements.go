@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/database"
+)
+
+type TierLimits struct {
+	MaxChirpLength    int
+	EditWindow        time.Duration
+	RequestsPerMinute int
+	WritesPerMinute   int
+	MaxPinnedChirps   int
+}
+
+type Entitlements struct {
+	Tier                    string `json:"tier"`
+	MaxChirpLength          int    `json:"max_chirp_length"`
+	CanEditChirps           bool   `json:"can_edit_chirps"`
+	EditWindowSeconds       int    `json:"edit_window_seconds"`
+	RateLimitPerMinute      int    `json:"rate_limit_per_minute"`
+	WriteRateLimitPerMinute int    `json:"write_rate_limit_per_minute"`
+	MaxPinnedChirps         int    `json:"max_pinned_chirps"`
+}
+
+const (
+	tierFree = "free"
+	tierRed  = "red"
+)
+
+var defaultTiers = map[string]TierLimits{
+	tierFree: {
+		MaxChirpLength:    140,
+		RequestsPerMinute: 60,
+		WritesPerMinute:   30,
+		MaxPinnedChirps:   1,
+	},
+	tierRed: {
+		MaxChirpLength:    280,
+		EditWindow:        30 * time.Minute,
+		RequestsPerMinute: 300,
+		WritesPerMinute:   120,
+		MaxPinnedChirps:   5,
+	},
+}
+
+func userTier(u database.User) string {
+	if u.IsChirpyRed.Valid && u.IsChirpyRed.Bool {
+		return tierRed
+	}
+	return tierFree
+}
+
+// tierLimits falls back to the defaults for tiers or values missing from the config.
+func (cfg *apiConfig) tierLimits(tier string) TierLimits {
+	limits, ok := cfg.Tiers[tier]
+	if !ok {
+		return defaultTiers[tier]
+	}
+	if limits.MaxChirpLength <= 0 {
+		limits.MaxChirpLength = defaultTiers[tier].MaxChirpLength
+	}
+	if limits.RequestsPerMinute <= 0 {
+		limits.RequestsPerMinute = defaultTiers[tier].RequestsPerMinute
+	}
+	if limits.WritesPerMinute <= 0 {
+		limits.WritesPerMinute = defaultTiers[tier].WritesPerMinute
+	}
+	if limits.MaxPinnedChirps <= 0 {
+		limits.MaxPinnedChirps = defaultTiers[tier].MaxPinnedChirps
+	}
+	return limits
+}
+
+func (cfg *apiConfig) entitlements(u database.User) Entitlements {
+	tier := userTier(u)
+	limits := cfg.tierLimits(tier)
+	return Entitlements{
+		Tier:                    tier,
+		MaxChirpLength:          limits.MaxChirpLength,
+		CanEditChirps:           limits.EditWindow > 0,
+		EditWindowSeconds:       int(limits.EditWindow.Seconds()),
+		RateLimitPerMinute:      limits.RequestsPerMinute,
+		WriteRateLimitPerMinute: limits.WritesPerMinute,
+		MaxPinnedChirps:         limits.MaxPinnedChirps,
+	}
+}
+
+func (cfg *apiConfig) handleGetEntitlements(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	respBody, _ := encodeJson(cfg.entitlements(dbUser))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
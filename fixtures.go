@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/auth"
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	fixturePassword = "password123"
+)
+
+var fixtureTime = time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+var fixtureUsers = []database.CreateUserParams{
+	{
+		ID:    uuid.MustParse("00000000-0000-4000-8000-000000000001"),
+		Email: "walt@breakingbad.com",
+	},
+	{
+		ID:    uuid.MustParse("00000000-0000-4000-8000-000000000002"),
+		Email: "saul@bettercall.com",
+	},
+	{
+		ID:    uuid.MustParse("00000000-0000-4000-8000-000000000003"),
+		Email: "mike@madrigal.com",
+	},
+}
+
+var fixtureModerators = []uuid.UUID{
+	fixtureUsers[2].ID,
+}
+
+var fixtureChirps = []database.CreateChirpParams{
+	{
+		ID:     uuid.MustParse("00000000-0000-4000-9000-000000000001"),
+		Body:   "I'm the one who knocks!",
+		UserID: fixtureUsers[0].ID,
+	},
+	{
+		ID:     uuid.MustParse("00000000-0000-4000-9000-000000000002"),
+		Body:   "Gale!",
+		UserID: fixtureUsers[0].ID,
+	},
+	{
+		ID:     uuid.MustParse("00000000-0000-4000-9000-000000000003"),
+		Body:   "Did you know that you have rights? The constitution says you do.",
+		UserID: fixtureUsers[1].ID,
+	},
+}
+
+func seedFixtures(ctx context.Context, q *database.Queries) error {
+	hash, err := auth.HashPassword(fixturePassword)
+	if err != nil {
+		return err
+	}
+
+	for _, params := range fixtureUsers {
+		params.CreatedAt = fixtureTime
+		params.UpdatedAt = fixtureTime
+		params.HashedPassword = hash
+		_, err = q.CreateUser(ctx, params)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, id := range fixtureModerators {
+		_, err = q.SetUserModerator(ctx, database.SetUserModeratorParams{
+			ID:          id,
+			IsModerator: true,
+			UpdatedAt:   fixtureTime,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, params := range fixtureChirps {
+		created := fixtureTime.Add(time.Duration(i) * time.Minute)
+		params.CreatedAt = created
+		params.UpdatedAt = created
+		params.Visibility = visibilityPublic
+		_, err = q.CreateChirp(ctx, params)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
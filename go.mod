@@ -4,8 +4,13 @@ go 1.23.2
 
 require (
 	github.com/golang-jwt/jwt/v5 v5.2.1
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
-	github.com/lib/pq v1.10.9 // indirect
+	github.com/google/uuid v1.6.0
+	github.com/gorilla/websocket v1.5.3
+	github.com/joho/godotenv v1.5.1
+	github.com/lib/pq v1.10.9
+	github.com/rivo/uniseg v0.4.7
 	golang.org/x/crypto v0.29.0
+	golang.org/x/image v0.22.0
+	golang.org/x/net v0.31.0
+	golang.org/x/text v0.20.0
 )
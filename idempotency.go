@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/database"
+)
+
+const (
+	idempotencyKeyHeader       = "Idempotency-Key"
+	idempotentReplayedHeader   = "Idempotent-Replayed"
+	maxIdempotencyKeyLength    = 255
+	idempotencyKeyTTL          = 24 * time.Hour
+	idempotencyLockTimeout     = time.Minute
+	idempotencyCleanupInterval = time.Hour
+)
+
+// idempotencyExemptRoutes hand out tokens, which shouldn't sit in the database
+// to be replayed, or dedupe their own deliveries.
+var idempotencyExemptRoutes = map[string]bool{
+	"/api/login":          true,
+	"/api/refresh":        true,
+	"/api/revoke":         true,
+	"/api/polka/webhooks": true,
+}
+
+// middlewareIdempotency makes POST requests sent with an Idempotency-Key safe
+// to retry. The first response for a key is stored for idempotencyKeyTTL and
+// replayed for retries with the same payload; reusing the key for a different
+// payload is refused with a 422. Failures that a retry might not hit again
+// aren't stored, so the retry runs the request again.
+func (cfg *apiConfig) middlewareIdempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(idempotencyKeyHeader)
+		if key == "" || req.Method != http.MethodPost ||
+			!strings.HasPrefix(req.URL.Path, "/api/") || idempotencyExemptRoutes[req.URL.Path] {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			returnErrorResponse(w, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		req.Body = http.MaxBytesReader(w, req.Body, int64(cfg.MaxMediaBytes)+multipartOverhead)
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			returnTooLarge(w)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Anonymous callers, i.e. signups, can't be told apart any better.
+		scope := "ip:" + clientIP(req, cfg.TrustProxy)
+		if userId, ok := cfg.bearerUserId(req); ok {
+			scope = userId.String()
+		}
+		hash := sha256.Sum256([]byte(req.URL.RequestURI() + "\n" + string(body)))
+		requestHash := hex.EncodeToString(hash[:])
+
+		now := time.Now().UTC()
+		reserved, err := cfg.dbQueries.ReserveIdempotencyKey(req.Context(), database.ReserveIdempotencyKeyParams{
+			Scope:         scope,
+			Key:           key,
+			RequestHash:   requestHash,
+			Now:           now,
+			LockedUntil:   now.Add(idempotencyLockTimeout),
+			ExpiredBefore: now.Add(-idempotencyKeyTTL),
+		})
+		if err != nil {
+			returnErrorResponse(w, standardError)
+			return
+		}
+		if reserved == 0 {
+			cfg.replayIdempotentResponse(w, req, scope, key, requestHash)
+			return
+		}
+
+		// The client may hang up before the response is stored; that's when
+		// it's most likely to retry.
+		ctx := context.WithoutCancel(req.Context())
+		recorder := &responseRecorder{ResponseWriter: w}
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			err := cfg.dbQueries.ReleaseIdempotencyKey(ctx, database.ReleaseIdempotencyKeyParams{
+				Scope: scope,
+				Key:   key,
+			})
+			if err != nil {
+				log.Printf("error releasing idempotency key: %s\n", err)
+			}
+		}()
+
+		next.ServeHTTP(recorder, req)
+
+		if !replayableResponse(recorder.statusCode(), recorder.body.Bytes()) {
+			return
+		}
+		err = cfg.dbQueries.CompleteIdempotencyKey(ctx, database.CompleteIdempotencyKeyParams{
+			StatusCode:   int32(recorder.statusCode()),
+			ContentType:  recorder.contentType,
+			ResponseBody: recorder.body.Bytes(),
+			CompletedAt:  sql.NullTime{Time: time.Now().UTC(), Valid: true},
+			Scope:        scope,
+			Key:          key,
+		})
+		if err != nil {
+			log.Printf("error storing idempotent response: %s\n", err)
+			return
+		}
+		completed = true
+	})
+}
+
+// replayableResponse picks the responses a retry should get back: successes
+// and client errors. Handlers report internal failures as a 400 with
+// standardError, so those are left out along with 5xx and 429.
+func replayableResponse(status int, body []byte) bool {
+	switch {
+	case status >= 200 && status < 300:
+		return true
+	case status == http.StatusTooManyRequests || status < 400 || status >= 500:
+		return false
+	case status == http.StatusBadRequest:
+		errorResponse := ErrorResponse{}
+		return json.Unmarshal(body, &errorResponse) != nil || errorResponse.Error != standardError
+	}
+	return true
+}
+
+func (cfg *apiConfig) replayIdempotentResponse(w http.ResponseWriter, req *http.Request, scope, key, requestHash string) {
+	stored, err := cfg.dbQueries.GetIdempotencyKey(req.Context(), database.GetIdempotencyKeyParams{
+		Scope: scope,
+		Key:   key,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Released by a failed request since we tried to reserve it.
+		returnIdempotencyError(w, http.StatusConflict, "A request with this Idempotency-Key failed, retry it")
+		return
+	}
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if stored.RequestHash != requestHash {
+		returnIdempotencyError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+		return
+	}
+	if !stored.CompletedAt.Valid {
+		returnIdempotencyError(w, http.StatusConflict, "A request with this Idempotency-Key is still in progress")
+		return
+	}
+
+	if stored.ContentType != "" {
+		w.Header().Set(contentType, stored.ContentType)
+	}
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.WriteHeader(int(stored.StatusCode))
+	w.Write(stored.ResponseBody)
+}
+
+func returnIdempotencyError(w http.ResponseWriter, status int, errorString string) {
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(status)
+	respBody, _ := encodeJson(ErrorResponse{
+		Error: errorString,
+	})
+	w.Write(respBody)
+}
+
+// responseRecorder copies a response as it's written so it can be stored.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	contentType string
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+		// Headers set after this point never reach the client either.
+		r.contentType = r.Header().Get(contentType)
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+func (cfg *apiConfig) runIdempotencyKeyCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := cfg.dbQueries.DeleteExpiredIdempotencyKeys(ctx, time.Now().UTC().Add(-idempotencyKeyTTL))
+			if err != nil {
+				log.Printf("error deleting expired idempotency keys: %s\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestReplayableResponse(t *testing.T) {
+	internal, _ := encodeJson(ErrorResponse{Error: standardError})
+	invalid, _ := encodeJson(ErrorResponse{Error: "Chirp is too long"})
+	cases := []struct {
+		status   int
+		body     []byte
+		expected bool
+	}{
+		{http.StatusCreated, []byte(`{"id":"1"}`), true},
+		{http.StatusNoContent, nil, true},
+		{http.StatusBadRequest, invalid, true},
+		{http.StatusBadRequest, internal, false},
+		{http.StatusNotFound, nil, true},
+		{http.StatusTooManyRequests, nil, false},
+		{http.StatusInternalServerError, nil, false},
+		{http.StatusBadGateway, nil, false},
+	}
+	for _, c := range cases {
+		if got := replayableResponse(c.status, c.body); got != c.expected {
+			t.Errorf("replayableResponse(%d, %s) = %v, expected %v", c.status, c.body, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	webhookSecret = "polka-secret"
+	webhookBody   = `{"event":"user.upgraded","data":{"user_id":"3311741c-680c-4546-99f3-fc9efac2036c"}}`
+)
+
+func TestWebhookSignature(t *testing.T) {
+	now := time.Now()
+	header := SignWebhook(webhookSecret, []byte(webhookBody), now)
+	err := VerifyWebhook(webhookSecret, header, []byte(webhookBody), 5*time.Minute, now)
+	if err != nil {
+		t.Errorf("expected valid signature, got: %v", err)
+	}
+}
+
+func TestWebhookSignatureMismatch(t *testing.T) {
+	now := time.Now()
+	header := SignWebhook(webhookSecret, []byte(webhookBody), now)
+
+	err := VerifyWebhook("wrong-secret", header, []byte(webhookBody), 5*time.Minute, now)
+	if !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("expected mismatch for wrong secret, got: %v", err)
+	}
+
+	tampered := strings.Replace(webhookBody, "upgraded", "downgraded", 1)
+	err = VerifyWebhook(webhookSecret, header, []byte(tampered), 5*time.Minute, now)
+	if !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("expected mismatch for tampered body, got: %v", err)
+	}
+}
+
+func TestWebhookSignatureExpired(t *testing.T) {
+	now := time.Now()
+	header := SignWebhook(webhookSecret, []byte(webhookBody), now.Add(-10*time.Minute))
+	err := VerifyWebhook(webhookSecret, header, []byte(webhookBody), 5*time.Minute, now)
+	if !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("expected expired signature, got: %v", err)
+	}
+}
+
+func TestMalformedWebhookSignature(t *testing.T) {
+	headers := []string{
+		"",
+		"v1=abcd",
+		"t=123",
+		"t=abc,v1=abcd",
+		"t=123,v1=not-hex",
+		"garbage",
+	}
+	for _, header := range headers {
+		err := VerifyWebhook(webhookSecret, header, []byte(webhookBody), 5*time.Minute, time.Now())
+		if !errors.Is(err, ErrMalformedSignature) {
+			t.Errorf("expected malformed error for %q, got: %v", header, err)
+		}
+	}
+}
+
+func TestWebhookSignatureWithoutSecret(t *testing.T) {
+	now := time.Now()
+	header := SignWebhook("", []byte(webhookBody), now)
+	err := VerifyWebhook("", header, []byte(webhookBody), 5*time.Minute, now)
+	if !errors.Is(err, ErrMissingSecret) {
+		t.Errorf("expected an empty secret to be refused, got: %v", err)
+	}
+}
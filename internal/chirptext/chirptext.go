@@ -0,0 +1,88 @@
+package chirptext
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// URLLength is the weight of every link, however long the URL really is.
+const URLLength = 23
+
+var urlPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s]+`)
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.%+\-]+@[\w.\-]+\.\pL{2,})`)
+
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&#])#([\p{L}\p{N}_]+)`)
+
+// Normalize converts text to NFC so that equivalent strings are stored and
+// counted the same way.
+func Normalize(text string) string {
+	return norm.NFC.String(text)
+}
+
+// Length counts user-perceived characters (grapheme clusters), so an emoji
+// with modifiers or a letter with combining accents counts once. Each URL
+// counts as URLLength characters.
+func Length(text string) int {
+	length := 0
+	last := 0
+	for _, match := range urlPattern.FindAllStringIndex(text, -1) {
+		length += uniseg.GraphemeClusterCount(text[last:match[0]])
+		length += URLLength
+		last = match[1]
+	}
+	return length + uniseg.GraphemeClusterCount(text[last:])
+}
+
+// Hashtags returns the lowercased tags in text, without the leading "#" and
+// in order of first appearance. Tags inside links are ignored.
+func Hashtags(text string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	text = urlPattern.ReplaceAllString(text, " ")
+	for _, match := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+		tag := strings.ToLower(match[1])
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// Mentions returns the lowercased email addresses mentioned as "@<email>",
+// e.g. "@walt@breakingbad.com", in order of first appearance.
+func Mentions(text string) []string {
+	mentions := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		email := strings.ToLower(match[1])
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		mentions = append(mentions, email)
+	}
+	return mentions
+}
+
+// Links returns the http(s) URLs in text in order of first appearance.
+// Punctuation right after a link, like a closing bracket or a full stop, is
+// left out.
+func Links(text string) []string {
+	links := []string{}
+	seen := map[string]bool{}
+	for _, link := range urlPattern.FindAllString(text, -1) {
+		link = strings.TrimRight(link, ".,;:!?)]}'\"")
+		if seen[link] {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	return links
+}
@@ -0,0 +1,93 @@
+package chirptext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLength(t *testing.T) {
+	cases := map[string]int{
+		"":                        0,
+		"hello":                   5,
+		"café":                    4,
+		"cafe\u0301":              4,
+		"👍🏽":                      1,
+		"👨‍👩‍👧‍👦 family":          8,
+		"🇺🇸🇲🇽":                    2,
+		"see https://example.com": 4 + URLLength,
+		"https://a.co/" + strings.Repeat("x", 100) + " and http://b.io": URLLength + 5 + URLLength,
+	}
+	for input, expected := range cases {
+		actual := Length(input)
+		if actual != expected {
+			t.Errorf("Length(%q): expected %d, got %d\n", input, expected, actual)
+		}
+	}
+}
+
+func TestLengthOfEmojiChirp(t *testing.T) {
+	chirp := strings.Repeat("😀", 140)
+	if len(chirp) <= 140 {
+		t.Fatalf("expected the byte length to exceed 140, got %d\n", len(chirp))
+	}
+	if Length(chirp) != 140 {
+		t.Errorf("expected 140 characters, got %d\n", Length(chirp))
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	decomposed := "cafe\u0301"
+	composed := "café"
+	if Normalize(decomposed) != composed {
+		t.Errorf("expected %q to normalize to %q, got %q\n", decomposed, composed, Normalize(decomposed))
+	}
+}
+
+func TestHashtags(t *testing.T) {
+	cases := map[string][]string{
+		"":                                  {},
+		"no tags here":                      {},
+		"#Go is fun #golang #go":            {"go", "golang"},
+		"(#café) and #日本":                   {"café", "日本"},
+		"email me@x#notatag and ##twice":    {},
+		"https://example.com/#anchor #real": {"real"},
+	}
+	for input, expected := range cases {
+		actual := Hashtags(input)
+		if strings.Join(actual, ",") != strings.Join(expected, ",") {
+			t.Errorf("Hashtags(%q): expected %v, got %v\n", input, expected, actual)
+		}
+	}
+}
+
+func TestMentions(t *testing.T) {
+	cases := map[string][]string{
+		"":                           {},
+		"hello walt@breakingbad.com": {},
+		"hi @walt@breakingbad.com.":  {"walt@breakingbad.com"},
+		"@Saul@BetterCall.com and @saul@bettercall.com": {"saul@bettercall.com"},
+		"(@mike@madrigal.com), @jesse":                  {"mike@madrigal.com"},
+	}
+	for input, expected := range cases {
+		actual := Mentions(input)
+		if strings.Join(actual, ",") != strings.Join(expected, ",") {
+			t.Errorf("Mentions(%q): expected %v, got %v\n", input, expected, actual)
+		}
+	}
+}
+
+func TestLinks(t *testing.T) {
+	cases := map[string][]string{
+		"":                         {},
+		"no links here":            {},
+		"see https://example.com.": {"https://example.com"},
+		"(http://a.co/x?y=1) and HTTPS://b.io/, http://a.co/x?y=1": {"http://a.co/x?y=1", "HTTPS://b.io/"},
+		"ftp://example.com": {},
+	}
+	for input, expected := range cases {
+		actual := Links(input)
+		if strings.Join(actual, ",") != strings.Join(expected, ",") {
+			t.Errorf("Links(%q): expected %v, got %v\n", input, expected, actual)
+		}
+	}
+}
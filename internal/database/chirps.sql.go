@@ -7,11 +7,24 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const countPinnedChirps = `-- name: CountPinnedChirps :one
+SELECT count(*) FROM chirps
+    WHERE user_id=$1 AND pinned_at IS NOT NULL
+`
+
+func (q *Queries) CountPinnedChirps(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPinnedChirps, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createChirp = `-- name: CreateChirp :one
 
 INSERT INTO chirps(
@@ -19,18 +32,28 @@ INSERT INTO chirps(
     created_at,
     updated_at,
     body,
-    user_id
+    user_id,
+    reply_to_id,
+    visibility,
+    content_warning,
+    sensitive,
+    link_url
 )
-    VALUES($1, $2, $3, $4, $5)
-    RETURNING id, created_at, updated_at, body, user_id
+    VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    RETURNING id, created_at, updated_at, body, user_id, hidden_at, reply_to_id, visibility, deleted_at, pinned_at, content_warning, sensitive, link_url
 `
 
 type CreateChirpParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Body      string
-	UserID    uuid.UUID
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Body           string
+	UserID         uuid.UUID
+	ReplyToID      uuid.NullUUID
+	Visibility     string
+	ContentWarning string
+	Sensitive      bool
+	LinkUrl        string
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
@@ -40,6 +63,11 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		arg.UpdatedAt,
 		arg.Body,
 		arg.UserID,
+		arg.ReplyToID,
+		arg.Visibility,
+		arg.ContentWarning,
+		arg.Sensitive,
+		arg.LinkUrl,
 	)
 	var i Chirp
 	err := row.Scan(
@@ -48,6 +76,14 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.HiddenAt,
+		&i.ReplyToID,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.PinnedAt,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.LinkUrl,
 	)
 	return i, err
 }
@@ -65,7 +101,7 @@ func (q *Queries) DeleteAllChirps(ctx context.Context) error {
 const deleteChirpById = `-- name: DeleteChirpById :one
 DELETE FROM chirps
     WHERE id=$1
-    RETURNING id, created_at, updated_at, body, user_id
+    RETURNING id, created_at, updated_at, body, user_id, hidden_at, reply_to_id, visibility, deleted_at, pinned_at, content_warning, sensitive, link_url
 `
 
 func (q *Queries) DeleteChirpById(ctx context.Context, id uuid.UUID) (Chirp, error) {
@@ -77,17 +113,46 @@ func (q *Queries) DeleteChirpById(ctx context.Context, id uuid.UUID) (Chirp, err
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.HiddenAt,
+		&i.ReplyToID,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.PinnedAt,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.LinkUrl,
 	)
 	return i, err
 }
 
 const getAllChirpsAsc = `-- name: GetAllChirpsAsc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at ASC
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden_at, chirps.reply_to_id, chirps.visibility, chirps.deleted_at, chirps.pinned_at, chirps.content_warning, chirps.sensitive, chirps.link_url FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = $1)
+                OR (blocker_id = $1 AND blocked_id = chirps.user_id)
+    )
+    AND chirps.visibility <> 'unlisted'
+    AND (chirps.visibility = 'public'
+        OR chirps.user_id = $1
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = $1 AND followee_id = chirps.user_id
+        )))
+    AND NOT EXISTS (
+        SELECT 1 FROM mutes
+            WHERE muter_id = $1 AND muted_id = chirps.user_id
+    )
+ORDER BY chirps.created_at ASC
 `
 
-func (q *Queries) GetAllChirpsAsc(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirpsAsc)
+func (q *Queries) GetAllChirpsAsc(ctx context.Context, viewerID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getAllChirpsAsc, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -101,6 +166,14 @@ func (q *Queries) GetAllChirpsAsc(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.HiddenAt,
+			&i.ReplyToID,
+			&i.Visibility,
+			&i.DeletedAt,
+			&i.PinnedAt,
+			&i.ContentWarning,
+			&i.Sensitive,
+			&i.LinkUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -116,12 +189,33 @@ func (q *Queries) GetAllChirpsAsc(ctx context.Context) ([]Chirp, error) {
 }
 
 const getAllChirpsDesc = `-- name: GetAllChirpsDesc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at DESC
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden_at, chirps.reply_to_id, chirps.visibility, chirps.deleted_at, chirps.pinned_at, chirps.content_warning, chirps.sensitive, chirps.link_url FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = $1)
+                OR (blocker_id = $1 AND blocked_id = chirps.user_id)
+    )
+    AND chirps.visibility <> 'unlisted'
+    AND (chirps.visibility = 'public'
+        OR chirps.user_id = $1
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = $1 AND followee_id = chirps.user_id
+        )))
+    AND NOT EXISTS (
+        SELECT 1 FROM mutes
+            WHERE muter_id = $1 AND muted_id = chirps.user_id
+    )
+ORDER BY chirps.created_at DESC
 `
 
-func (q *Queries) GetAllChirpsDesc(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirpsDesc)
+func (q *Queries) GetAllChirpsDesc(ctx context.Context, viewerID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getAllChirpsDesc, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -135,6 +229,14 @@ func (q *Queries) GetAllChirpsDesc(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.HiddenAt,
+			&i.ReplyToID,
+			&i.Visibility,
+			&i.DeletedAt,
+			&i.PinnedAt,
+			&i.ContentWarning,
+			&i.Sensitive,
+			&i.LinkUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -149,14 +251,63 @@ func (q *Queries) GetAllChirpsDesc(ctx context.Context) ([]Chirp, error) {
 	return items, nil
 }
 
+const getAnyChirpById = `-- name: GetAnyChirpById :one
+SELECT id, created_at, updated_at, body, user_id, hidden_at, reply_to_id, visibility, deleted_at, pinned_at, content_warning, sensitive, link_url FROM chirps
+WHERE id = $1
+`
+
+// Like GetChirpById, but includes chirps in the trash. Only for moderation
+// and restores.
+func (q *Queries) GetAnyChirpById(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getAnyChirpById, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.ReplyToID,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.PinnedAt,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.LinkUrl,
+	)
+	return i, err
+}
+
 const getChirpByAuthorIdAsc = `-- name: GetChirpByAuthorIdAsc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-    WHERE user_id=$1
-    ORDER BY created_at ASC
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden_at, chirps.reply_to_id, chirps.visibility, chirps.deleted_at, chirps.pinned_at, chirps.content_warning, chirps.sensitive, chirps.link_url FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.user_id = $1
+    AND chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = $2)
+                OR (blocker_id = $2 AND blocked_id = chirps.user_id)
+    )
+    AND (chirps.visibility IN ('public', 'unlisted')
+        OR chirps.user_id = $2
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = $2 AND followee_id = chirps.user_id
+        )))
+ORDER BY chirps.created_at ASC
 `
 
-func (q *Queries) GetChirpByAuthorIdAsc(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpByAuthorIdAsc, userID)
+type GetChirpByAuthorIdAscParams struct {
+	UserID   uuid.UUID
+	ViewerID uuid.UUID
+}
+
+func (q *Queries) GetChirpByAuthorIdAsc(ctx context.Context, arg GetChirpByAuthorIdAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpByAuthorIdAsc, arg.UserID, arg.ViewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -170,6 +321,14 @@ func (q *Queries) GetChirpByAuthorIdAsc(ctx context.Context, userID uuid.UUID) (
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.HiddenAt,
+			&i.ReplyToID,
+			&i.Visibility,
+			&i.DeletedAt,
+			&i.PinnedAt,
+			&i.ContentWarning,
+			&i.Sensitive,
+			&i.LinkUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -185,13 +344,34 @@ func (q *Queries) GetChirpByAuthorIdAsc(ctx context.Context, userID uuid.UUID) (
 }
 
 const getChirpByAuthorIdDesc = `-- name: GetChirpByAuthorIdDesc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-    WHERE user_id=$1
-    ORDER BY created_at DESC
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden_at, chirps.reply_to_id, chirps.visibility, chirps.deleted_at, chirps.pinned_at, chirps.content_warning, chirps.sensitive, chirps.link_url FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.user_id = $1
+    AND chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = $2)
+                OR (blocker_id = $2 AND blocked_id = chirps.user_id)
+    )
+    AND (chirps.visibility IN ('public', 'unlisted')
+        OR chirps.user_id = $2
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = $2 AND followee_id = chirps.user_id
+        )))
+ORDER BY chirps.created_at DESC
 `
 
-func (q *Queries) GetChirpByAuthorIdDesc(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpByAuthorIdDesc, userID)
+type GetChirpByAuthorIdDescParams struct {
+	UserID   uuid.UUID
+	ViewerID uuid.UUID
+}
+
+func (q *Queries) GetChirpByAuthorIdDesc(ctx context.Context, arg GetChirpByAuthorIdDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpByAuthorIdDesc, arg.UserID, arg.ViewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -205,6 +385,14 @@ func (q *Queries) GetChirpByAuthorIdDesc(ctx context.Context, userID uuid.UUID)
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.HiddenAt,
+			&i.ReplyToID,
+			&i.Visibility,
+			&i.DeletedAt,
+			&i.PinnedAt,
+			&i.ContentWarning,
+			&i.Sensitive,
+			&i.LinkUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -220,8 +408,8 @@ func (q *Queries) GetChirpByAuthorIdDesc(ctx context.Context, userID uuid.UUID)
 }
 
 const getChirpById = `-- name: GetChirpById :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE id = $1
+SELECT id, created_at, updated_at, body, user_id, hidden_at, reply_to_id, visibility, deleted_at, pinned_at, content_warning, sensitive, link_url FROM chirps
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetChirpById(ctx context.Context, id uuid.UUID) (Chirp, error) {
@@ -233,12 +421,21 @@ func (q *Queries) GetChirpById(ctx context.Context, id uuid.UUID) (Chirp, error)
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.HiddenAt,
+		&i.ReplyToID,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.PinnedAt,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.LinkUrl,
 	)
 	return i, err
 }
 
 const getChirpsCount = `-- name: GetChirpsCount :one
 SELECT count(*) FROM chirps
+WHERE deleted_at IS NULL
 `
 
 func (q *Queries) GetChirpsCount(ctx context.Context) (int64, error) {
@@ -247,3 +444,435 @@ func (q *Queries) GetChirpsCount(ctx context.Context) (int64, error) {
 	err := row.Scan(&count)
 	return count, err
 }
+
+const getPinnedChirps = `-- name: GetPinnedChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden_at, chirps.reply_to_id, chirps.visibility, chirps.deleted_at, chirps.pinned_at, chirps.content_warning, chirps.sensitive, chirps.link_url FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.user_id = $1
+    AND chirps.pinned_at IS NOT NULL
+    AND chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = $2)
+                OR (blocker_id = $2 AND blocked_id = chirps.user_id)
+    )
+    AND (chirps.visibility IN ('public', 'unlisted')
+        OR chirps.user_id = $2
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = $2 AND followee_id = chirps.user_id
+        )))
+ORDER BY chirps.pinned_at DESC
+`
+
+type GetPinnedChirpsParams struct {
+	UserID   uuid.UUID
+	ViewerID uuid.UUID
+}
+
+func (q *Queries) GetPinnedChirps(ctx context.Context, arg GetPinnedChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getPinnedChirps, arg.UserID, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.HiddenAt,
+			&i.ReplyToID,
+			&i.Visibility,
+			&i.DeletedAt,
+			&i.PinnedAt,
+			&i.ContentWarning,
+			&i.Sensitive,
+			&i.LinkUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPublicChirpById = `-- name: GetPublicChirpById :one
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden_at, chirps.reply_to_id, chirps.visibility, chirps.deleted_at, chirps.pinned_at, chirps.content_warning, chirps.sensitive, chirps.link_url FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.id = $1
+    AND chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = $2)
+                OR (blocker_id = $2 AND blocked_id = chirps.user_id)
+    )
+    AND (chirps.visibility IN ('public', 'unlisted')
+        OR chirps.user_id = $2
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = $2 AND followee_id = chirps.user_id
+        )))
+`
+
+type GetPublicChirpByIdParams struct {
+	ID       uuid.UUID
+	ViewerID uuid.UUID
+}
+
+// Like GetChirpById, but skips hidden chirps, chirps by suspended or banned
+// authors, chirps by users who blocked or were blocked by viewer_id, and
+// chirps whose visibility doesn't allow viewer_id to read them.
+func (q *Queries) GetPublicChirpById(ctx context.Context, arg GetPublicChirpByIdParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getPublicChirpById, arg.ID, arg.ViewerID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.ReplyToID,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.PinnedAt,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.LinkUrl,
+	)
+	return i, err
+}
+
+const getTrashedChirps = `-- name: GetTrashedChirps :many
+SELECT id, created_at, updated_at, body, user_id, hidden_at, reply_to_id, visibility, deleted_at, pinned_at, content_warning, sensitive, link_url FROM chirps
+WHERE user_id = $1
+    AND deleted_at > $2::timestamp
+ORDER BY deleted_at DESC
+LIMIT $3
+OFFSET $4
+`
+
+type GetTrashedChirpsParams struct {
+	UserID       uuid.UUID
+	DeletedAfter time.Time
+	MaxResults   int32
+	Skip         int32
+}
+
+func (q *Queries) GetTrashedChirps(ctx context.Context, arg GetTrashedChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getTrashedChirps,
+		arg.UserID,
+		arg.DeletedAfter,
+		arg.MaxResults,
+		arg.Skip,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.HiddenAt,
+			&i.ReplyToID,
+			&i.Visibility,
+			&i.DeletedAt,
+			&i.PinnedAt,
+			&i.ContentWarning,
+			&i.Sensitive,
+			&i.LinkUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hideChirp = `-- name: HideChirp :one
+UPDATE chirps
+    SET hidden_at=$2,
+        updated_at=$3
+    WHERE id=$1
+    RETURNING id, created_at, updated_at, body, user_id, hidden_at, reply_to_id, visibility, deleted_at, pinned_at, content_warning, sensitive, link_url
+`
+
+type HideChirpParams struct {
+	ID        uuid.UUID
+	HiddenAt  sql.NullTime
+	UpdatedAt time.Time
+}
+
+func (q *Queries) HideChirp(ctx context.Context, arg HideChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, hideChirp, arg.ID, arg.HiddenAt, arg.UpdatedAt)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.ReplyToID,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.PinnedAt,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.LinkUrl,
+	)
+	return i, err
+}
+
+const pinChirp = `-- name: PinChirp :one
+UPDATE chirps
+    SET pinned_at=$2
+    WHERE id=$1 AND deleted_at IS NULL
+    RETURNING id, created_at, updated_at, body, user_id, hidden_at, reply_to_id, visibility, deleted_at, pinned_at, content_warning, sensitive, link_url
+`
+
+type PinChirpParams struct {
+	ID       uuid.UUID
+	PinnedAt sql.NullTime
+}
+
+func (q *Queries) PinChirp(ctx context.Context, arg PinChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, pinChirp, arg.ID, arg.PinnedAt)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.ReplyToID,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.PinnedAt,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.LinkUrl,
+	)
+	return i, err
+}
+
+const purgeDeletedChirps = `-- name: PurgeDeletedChirps :execrows
+DELETE FROM chirps
+    WHERE id IN (
+        SELECT id FROM chirps
+            WHERE deleted_at <= $1::timestamp
+            LIMIT $2
+    )
+`
+
+type PurgeDeletedChirpsParams struct {
+	DeletedBefore time.Time
+	MaxChirps     int32
+}
+
+func (q *Queries) PurgeDeletedChirps(ctx context.Context, arg PurgeDeletedChirpsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedChirps, arg.DeletedBefore, arg.MaxChirps)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreChirp = `-- name: RestoreChirp :one
+UPDATE chirps
+    SET deleted_at = NULL
+    WHERE id = $1
+        AND user_id = $2
+        AND deleted_at > $3::timestamp
+        AND hidden_at IS NULL
+    RETURNING id, created_at, updated_at, body, user_id, hidden_at, reply_to_id, visibility, deleted_at, pinned_at, content_warning, sensitive, link_url
+`
+
+type RestoreChirpParams struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	DeletedAfter time.Time
+}
+
+// Only chirps deleted after deleted_after, i.e. still in the retention
+// window, can be restored. Chirps removed by a moderator are also hidden and
+// stay in the trash.
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, restoreChirp, arg.ID, arg.UserID, arg.DeletedAfter)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.ReplyToID,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.PinnedAt,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.LinkUrl,
+	)
+	return i, err
+}
+
+const setChirpContentWarning = `-- name: SetChirpContentWarning :one
+UPDATE chirps
+    SET content_warning=$2,
+        sensitive=$3,
+        updated_at=$4
+    WHERE id=$1
+    RETURNING id, created_at, updated_at, body, user_id, hidden_at, reply_to_id, visibility, deleted_at, pinned_at, content_warning, sensitive, link_url
+`
+
+type SetChirpContentWarningParams struct {
+	ID             uuid.UUID
+	ContentWarning string
+	Sensitive      bool
+	UpdatedAt      time.Time
+}
+
+func (q *Queries) SetChirpContentWarning(ctx context.Context, arg SetChirpContentWarningParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, setChirpContentWarning,
+		arg.ID,
+		arg.ContentWarning,
+		arg.Sensitive,
+		arg.UpdatedAt,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.ReplyToID,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.PinnedAt,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.LinkUrl,
+	)
+	return i, err
+}
+
+const softDeleteChirp = `-- name: SoftDeleteChirp :one
+UPDATE chirps
+    SET deleted_at=$2,
+        pinned_at=NULL
+    WHERE id=$1 AND deleted_at IS NULL
+    RETURNING id, created_at, updated_at, body, user_id, hidden_at, reply_to_id, visibility, deleted_at, pinned_at, content_warning, sensitive, link_url
+`
+
+type SoftDeleteChirpParams struct {
+	ID        uuid.UUID
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) SoftDeleteChirp(ctx context.Context, arg SoftDeleteChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, softDeleteChirp, arg.ID, arg.DeletedAt)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.ReplyToID,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.PinnedAt,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.LinkUrl,
+	)
+	return i, err
+}
+
+const unpinChirp = `-- name: UnpinChirp :exec
+UPDATE chirps
+    SET pinned_at=NULL
+    WHERE id=$1
+`
+
+func (q *Queries) UnpinChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, unpinChirp, id)
+	return err
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+    SET body=$2,
+        updated_at=$3,
+        link_url=$4
+    WHERE id=$1
+    RETURNING id, created_at, updated_at, body, user_id, hidden_at, reply_to_id, visibility, deleted_at, pinned_at, content_warning, sensitive, link_url
+`
+
+type UpdateChirpBodyParams struct {
+	ID        uuid.UUID
+	Body      string
+	UpdatedAt time.Time
+	LinkUrl   string
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody,
+		arg.ID,
+		arg.Body,
+		arg.UpdatedAt,
+		arg.LinkUrl,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.ReplyToID,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.PinnedAt,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.LinkUrl,
+	)
+	return i, err
+}
@@ -11,12 +11,190 @@ import (
 	"github.com/google/uuid"
 )
 
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
+type Bookmark struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
 type Chirp struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Body           string
+	UserID         uuid.UUID
+	HiddenAt       sql.NullTime
+	ReplyToID      uuid.NullUUID
+	Visibility     string
+	DeletedAt      sql.NullTime
+	PinnedAt       sql.NullTime
+	ContentWarning string
+	Sensitive      bool
+	LinkUrl        string
+}
+
+type Conversation struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserOneID uuid.UUID
+	UserTwoID uuid.UUID
+}
+
+type FilterWord struct {
+	Word      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Action    string
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type IdempotencyKey struct {
+	Scope        string
+	Key          string
+	RequestHash  string
+	StatusCode   int32
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	LockedUntil  time.Time
+	CompletedAt  sql.NullTime
+}
+
+type Like struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type LinkPreview struct {
+	Url           string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Status        string
+	Attempts      int32
+	NextAttemptAt time.Time
+	Title         string
+	Description   string
+	ImageUrl      string
+	SiteName      string
+	LastError     string
+	FetchedAt     sql.NullTime
+}
+
+type List struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Body      string
 	UserID    uuid.UUID
+	Name      string
+}
+
+type ListMember struct {
+	ListID    uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type Medium struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UserID        uuid.UUID
+	ChirpID       uuid.NullUUID
+	Position      int32
+	ContentType   string
+	SizeBytes     int32
+	Width         int32
+	Height        int32
+	StorageKey    string
+	ThumbnailKey  string
+	ThumbnailType string
+}
+
+type Message struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	ConversationID uuid.UUID
+	SenderID       uuid.UUID
+	Body           string
+	ReadAt         sql.NullTime
+}
+
+type ModerationAction struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	ModeratorID uuid.UUID
+	Action      string
+	ReportID    uuid.NullUUID
+	ChirpID     uuid.NullUUID
+	UserID      uuid.NullUUID
+	Notes       string
+}
+
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type Notification struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	ActorID   uuid.UUID
+	Type      string
+	ChirpID   uuid.NullUUID
+	ReadAt    sql.NullTime
+}
+
+type NotificationPreference struct {
+	UserID    uuid.UUID
+	Type      string
+	Muted     bool
+	UpdatedAt time.Time
+}
+
+type Poll struct {
+	ChirpID  uuid.UUID
+	ClosesAt time.Time
+}
+
+type PollOption struct {
+	ID       uuid.UUID
+	ChirpID  uuid.UUID
+	Position int32
+	Label    string
+}
+
+type PollVote struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	OptionID  uuid.UUID
+	CreatedAt time.Time
+}
+
+type RateLimitBucket struct {
+	Key       string
+	Tokens    float64
+	Allowed   bool
+	UpdatedAt time.Time
+}
+
+type Rechirp struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
 }
 
 type RefreshToken struct {
@@ -28,11 +206,116 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime
 }
 
+type Report struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ChirpID     uuid.NullUUID
+	ReporterID  uuid.NullUUID
+	Reason      string
+	Details     string
+	Status      string
+	ModeratorID uuid.NullUUID
+	Resolution  sql.NullString
+	ResolvedAt  sql.NullTime
+}
+
+type ScheduledChirp struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	UserID         uuid.UUID
+	Body           string
+	ReplyToID      uuid.NullUUID
+	Visibility     string
+	MediaIds       []uuid.UUID
+	Status         string
+	PublishAt      sql.NullTime
+	FailureReason  string
+	ContentWarning string
+	Sensitive      bool
+}
+
+type StreamEvent struct {
+	ID        int64
+	CreatedAt time.Time
+	Type      string
+	Payload   string
+}
+
+type Subscription struct {
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	UserID           uuid.UUID
+	Status           string
+	StartedAt        time.Time
+	CurrentPeriodEnd time.Time
+	GraceUntil       sql.NullTime
+	EndedAt          sql.NullTime
+}
+
+type SubscriptionEvent struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	SubscriptionID uuid.UUID
+	Event          string
+	Status         string
+}
+
 type User struct {
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Email            string
+	HashedPassword   string
+	IsChirpyRed      sql.NullBool
+	IsModerator      bool
+	SuspendedUntil   sql.NullTime
+	BannedAt         sql.NullTime
+	SuspensionReason string
+	DmsOpen          bool
+	IsProtected      bool
+	SensitiveContent string
+}
+
+type WebhookDelivery struct {
 	ID             uuid.UUID
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
-	Email          string
-	HashedPassword string
-	IsChirpyRed    sql.NullBool
+	EndpointID     uuid.UUID
+	Event          string
+	Payload        string
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastStatusCode int32
+	LastError      string
+	DeliveredAt    sql.NullTime
+}
+
+type WebhookEndpoint struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	OwnerID   uuid.UUID
+	Scope     string
+	AppName   string
+	Url       string
+	Secret    string
+	Events    []string
+}
+
+type WebhookEvent struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Source      string
+	DeliveryID  string
+	Event       string
+	Payload     string
+	Status      string
+	Error       string
+	Attempts    int32
+	ProcessedAt sql.NullTime
 }
@@ -56,6 +56,15 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshToken
 	return i, err
 }
 
+const deleteAllRefreshTokens = `-- name: DeleteAllRefreshTokens :exec
+DELETE FROM refresh_tokens
+`
+
+func (q *Queries) DeleteAllRefreshTokens(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllRefreshTokens)
+	return err
+}
+
 const getRefreshToken = `-- name: GetRefreshToken :one
 SELECT token, created_at, updated_at, user_id, expires_at, revoked_at FROM refresh_tokens
     WHERE token = $1
@@ -75,6 +84,24 @@ func (q *Queries) GetRefreshToken(ctx context.Context, token string) (RefreshTok
 	return i, err
 }
 
+const revokeUserRefreshTokens = `-- name: RevokeUserRefreshTokens :exec
+UPDATE refresh_tokens
+    SET updated_at = $2,
+        revoked_at = $3
+    WHERE user_id = $1 AND revoked_at IS NULL
+`
+
+type RevokeUserRefreshTokensParams struct {
+	UserID    uuid.UUID
+	UpdatedAt time.Time
+	RevokedAt sql.NullTime
+}
+
+func (q *Queries) RevokeUserRefreshTokens(ctx context.Context, arg RevokeUserRefreshTokensParams) error {
+	_, err := q.db.ExecContext(ctx, revokeUserRefreshTokens, arg.UserID, arg.UpdatedAt, arg.RevokedAt)
+	return err
+}
+
 const updateRefreshToken = `-- name: UpdateRefreshToken :one
 UPDATE refresh_tokens
     SET updated_at = $2,
@@ -11,8 +11,51 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const banUser = `-- name: BanUser :one
+UPDATE users
+    SET banned_at=$2,
+        suspension_reason=$3,
+        updated_at=$4
+    WHERE id=$1
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content
+`
+
+type BanUserParams struct {
+	ID               uuid.UUID
+	BannedAt         sql.NullTime
+	SuspensionReason string
+	UpdatedAt        time.Time
+}
+
+func (q *Queries) BanUser(ctx context.Context, arg BanUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, banUser,
+		arg.ID,
+		arg.BannedAt,
+		arg.SuspensionReason,
+		arg.UpdatedAt,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users(
     id,
@@ -22,7 +65,7 @@ INSERT INTO users(
     hashed_password
 )
     VALUES($1, $2, $3, $4, $5)
-    returning id, created_at, updated_at, email, hashed_password, is_chirpy_red
+    returning id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content
 `
 
 type CreateUserParams struct {
@@ -49,6 +92,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
 	)
 	return i, err
 }
@@ -62,8 +112,42 @@ func (q *Queries) DeleteAllUsers(ctx context.Context) error {
 	return err
 }
 
+const downgradeUserFromRed = `-- name: DowngradeUserFromRed :one
+UPDATE users
+    SET is_chirpy_red=false,
+        updated_at=$2
+    WHERE id=$1
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content
+`
+
+type DowngradeUserFromRedParams struct {
+	ID        uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) DowngradeUserFromRed(ctx context.Context, arg DowngradeUserFromRedParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, downgradeUserFromRed, arg.ID, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
+	)
+	return i, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content FROM users
     WHERE email=$1
 `
 
@@ -77,12 +161,19 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content FROM users
     WHERE id=$1
 `
 
@@ -96,10 +187,59 @@ func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
 	)
 	return i, err
 }
 
+const getUsersByEmails = `-- name: GetUsersByEmails :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content FROM users
+    WHERE lower(email) = ANY($1::text[])
+`
+
+func (q *Queries) GetUsersByEmails(ctx context.Context, emails []string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByEmails, pq.Array(emails))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.IsModerator,
+			&i.SuspendedUntil,
+			&i.BannedAt,
+			&i.SuspensionReason,
+			&i.DmsOpen,
+			&i.IsProtected,
+			&i.SensitiveContent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUsersCount = `-- name: GetUsersCount :one
 SELECT count(*) FROM users
 `
@@ -111,13 +251,243 @@ func (q *Queries) GetUsersCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const lockUser = `-- name: LockUser :exec
+SELECT id FROM users
+    WHERE id=$1
+    FOR UPDATE
+`
+
+// Serializes changes that check a per-user limit before applying it.
+func (q *Queries) LockUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, lockUser, id)
+	return err
+}
+
+const setUserDMsOpen = `-- name: SetUserDMsOpen :one
+UPDATE users
+    SET dms_open=$2,
+        updated_at=$3
+    WHERE id=$1
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content
+`
+
+type SetUserDMsOpenParams struct {
+	ID        uuid.UUID
+	DmsOpen   bool
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetUserDMsOpen(ctx context.Context, arg SetUserDMsOpenParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserDMsOpen, arg.ID, arg.DmsOpen, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
+	)
+	return i, err
+}
+
+const setUserModerator = `-- name: SetUserModerator :one
+UPDATE users
+    SET is_moderator=$2,
+        updated_at=$3
+    WHERE id=$1
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content
+`
+
+type SetUserModeratorParams struct {
+	ID          uuid.UUID
+	IsModerator bool
+	UpdatedAt   time.Time
+}
+
+func (q *Queries) SetUserModerator(ctx context.Context, arg SetUserModeratorParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserModerator, arg.ID, arg.IsModerator, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
+	)
+	return i, err
+}
+
+const setUserProtected = `-- name: SetUserProtected :one
+UPDATE users
+    SET is_protected=$2,
+        updated_at=$3
+    WHERE id=$1
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content
+`
+
+type SetUserProtectedParams struct {
+	ID          uuid.UUID
+	IsProtected bool
+	UpdatedAt   time.Time
+}
+
+func (q *Queries) SetUserProtected(ctx context.Context, arg SetUserProtectedParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserProtected, arg.ID, arg.IsProtected, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
+	)
+	return i, err
+}
+
+const setUserSensitiveContent = `-- name: SetUserSensitiveContent :one
+UPDATE users
+    SET sensitive_content=$2,
+        updated_at=$3
+    WHERE id=$1
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content
+`
+
+type SetUserSensitiveContentParams struct {
+	ID               uuid.UUID
+	SensitiveContent string
+	UpdatedAt        time.Time
+}
+
+func (q *Queries) SetUserSensitiveContent(ctx context.Context, arg SetUserSensitiveContentParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserSensitiveContent, arg.ID, arg.SensitiveContent, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
+	)
+	return i, err
+}
+
+const suspendUser = `-- name: SuspendUser :one
+UPDATE users
+    SET suspended_until=$2,
+        suspension_reason=$3,
+        updated_at=$4
+    WHERE id=$1
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content
+`
+
+type SuspendUserParams struct {
+	ID               uuid.UUID
+	SuspendedUntil   sql.NullTime
+	SuspensionReason string
+	UpdatedAt        time.Time
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, suspendUser,
+		arg.ID,
+		arg.SuspendedUntil,
+		arg.SuspensionReason,
+		arg.UpdatedAt,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
+	)
+	return i, err
+}
+
+const unsuspendUser = `-- name: UnsuspendUser :one
+UPDATE users
+    SET suspended_until=NULL,
+        banned_at=NULL,
+        suspension_reason='',
+        updated_at=$2
+    WHERE id=$1
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content
+`
+
+type UnsuspendUserParams struct {
+	ID        uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UnsuspendUser(ctx context.Context, arg UnsuspendUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, unsuspendUser, arg.ID, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
     SET email=$2,
         hashed_password=$3,
         updated_at=$4
     WHERE id = $1
-    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content
 `
 
 type UpdateUserParams struct {
@@ -142,6 +512,13 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
 	)
 	return i, err
 }
@@ -151,7 +528,7 @@ UPDATE users
     SET is_chirpy_red=$2,
         updated_at=$3
     WHERE id=$1
-    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_moderator, suspended_until, banned_at, suspension_reason, dms_open, is_protected, sensitive_content
 `
 
 type UpgradeUserToRedParams struct {
@@ -170,6 +547,13 @@ func (q *Queries) UpgradeUserToRed(ctx context.Context, arg UpgradeUserToRedPara
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsModerator,
+		&i.SuspendedUntil,
+		&i.BannedAt,
+		&i.SuspensionReason,
+		&i.DmsOpen,
+		&i.IsProtected,
+		&i.SensitiveContent,
 	)
 	return i, err
 }
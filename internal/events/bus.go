@@ -0,0 +1,79 @@
+package events
+
+import (
+	"sync"
+)
+
+// Event is a message fanned out to subscribers. ID increases with every event
+// so clients can resume from the last one they saw; Key optionally routes the
+// event, e.g. to the user it is addressed to.
+type Event struct {
+	ID   int64
+	Key  string
+	Type string
+	Data []byte
+}
+
+// Bus fans events out to in-process subscribers. Publish never blocks: a
+// subscriber whose buffer is full is dropped and its channel closed, so one
+// slow client can't hold up the others.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+}
+
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	bus    *Bus
+	closed bool
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: map[*Subscription]struct{}{}}
+}
+
+func (b *Bus) Subscribe(buffer int) *Subscription {
+	ch := make(chan Event, buffer)
+	sub := &Subscription{C: ch, ch: ch, bus: b}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe removes the subscription and closes its channel. It is safe to
+// call more than once.
+func (s *Subscription) Unsubscribe() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.remove(s)
+}
+
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		select {
+		case sub.ch <- e:
+		default:
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribers returns the number of live subscriptions.
+func (b *Bus) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
+
+func (b *Bus) remove(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(b.subscribers, sub)
+	close(sub.ch)
+}
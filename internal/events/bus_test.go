@@ -0,0 +1,51 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestPublishFansOut(t *testing.T) {
+	bus := NewBus()
+	first := bus.Subscribe(1)
+	second := bus.Subscribe(1)
+
+	bus.Publish(Event{ID: 1, Type: "chirp.created"})
+
+	for _, sub := range []*Subscription{first, second} {
+		event := <-sub.C
+		if event.ID != 1 || event.Type != "chirp.created" {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	bus := NewBus()
+	slow := bus.Subscribe(1)
+	fast := bus.Subscribe(2)
+
+	bus.Publish(Event{ID: 1})
+	bus.Publish(Event{ID: 2})
+
+	if bus.Subscribers() != 1 {
+		t.Errorf("expected slow subscriber to be dropped, have %d subscribers", bus.Subscribers())
+	}
+	<-slow.C
+	if _, ok := <-slow.C; ok {
+		t.Errorf("expected slow subscriber channel to be closed")
+	}
+	if len(fast.C) != 2 {
+		t.Errorf("expected fast subscriber to get both events, got %d", len(fast.C))
+	}
+}
+
+func TestUnsubscribeTwice(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe(1)
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	if bus.Subscribers() != 0 {
+		t.Errorf("expected no subscribers, have %d", bus.Subscribers())
+	}
+	bus.Publish(Event{ID: 1})
+}
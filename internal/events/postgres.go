@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Loader turns a NOTIFY payload back into an event, e.g. by reading the row
+// the payload points at.
+type Loader func(ctx context.Context, payload string) (Event, error)
+
+// ListenPostgres forwards NOTIFYs on channel to the bus until ctx is done, so
+// every instance sharing the database sees events published by the others.
+// Publishers only NOTIFY; this listener is what delivers locally as well.
+func ListenPostgres(ctx context.Context, dsn, channel string, bus *Bus, load Loader) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("event listener: %s\n", err)
+		}
+	})
+	defer listener.Close()
+
+	err := listener.Listen(channel)
+	if err != nil {
+		return err
+	}
+
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case n := <-listener.Notify:
+			// A nil notification means the connection was re-established and
+			// some events may have been missed; clients recover them through
+			// Last-Event-ID when they reconnect.
+			if n == nil {
+				continue
+			}
+			event, err := load(ctx, n.Extra)
+			if err != nil {
+				log.Printf("event listener: loading %q: %s\n", n.Extra, err)
+				continue
+			}
+			bus.Publish(event)
+		case <-ping.C:
+			go listener.Ping()
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package filter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+type Action string
+
+const (
+	Mask   Action = "mask"
+	Flag   Action = "flag"
+	Reject Action = "reject"
+)
+
+const maskText = "****"
+
+// severity decides which action wins when a text matches several words.
+var severity = map[Action]int{
+	Mask:   1,
+	Flag:   2,
+	Reject: 3,
+}
+
+var leetFolds = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'@': 'a',
+	'$': 's',
+}
+
+type Filter struct {
+	mu    sync.RWMutex
+	words map[string]Action
+}
+
+type Result struct {
+	Text     string
+	Action   Action
+	Matches  []string
+	Rejected bool
+	Flagged  bool
+}
+
+func ParseAction(s string) (Action, error) {
+	action := Action(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := severity[action]; !ok {
+		return "", fmt.Errorf("invalid action: %q", s)
+	}
+	return action, nil
+}
+
+func New(words map[string]Action) *Filter {
+	f := &Filter{}
+	f.Replace(words)
+	return f
+}
+
+// Replace swaps the whole word list, e.g. after it was edited at runtime.
+func (f *Filter) Replace(words map[string]Action) {
+	normalized := make(map[string]Action, len(words))
+	for word, action := range words {
+		key := Normalize(word)
+		if key == "" {
+			continue
+		}
+		normalized[key] = action
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.words = normalized
+}
+
+func (f *Filter) Words() map[string]Action {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	words := make(map[string]Action, len(f.words))
+	for word, action := range f.words {
+		words[word] = action
+	}
+	return words
+}
+
+// Check masks every listed word in text. Words are compared after Unicode
+// normalization and leet folding, and only whole tokens match, so punctuation
+// around a word is kept while the word itself is replaced.
+func (f *Filter) Check(text string) Result {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	result := Result{}
+	var out strings.Builder
+	var token []rune
+	flush := func() {
+		if len(token) == 0 {
+			return
+		}
+		word := string(token)
+		token = token[:0]
+		action, match, ok := f.lookup(word)
+		if !ok {
+			out.WriteString(word)
+			return
+		}
+		result.Matches = append(result.Matches, match)
+		if severity[action] > severity[result.Action] {
+			result.Action = action
+		}
+		if action == Mask {
+			out.WriteString(maskText)
+			return
+		}
+		out.WriteString(word)
+	}
+
+	for _, r := range text {
+		if isWordRune(r) {
+			token = append(token, r)
+			continue
+		}
+		flush()
+		out.WriteRune(r)
+	}
+	flush()
+
+	result.Text = out.String()
+	result.Rejected = result.Action == Reject
+	result.Flagged = result.Action == Flag
+	return result
+}
+
+func (f *Filter) lookup(word string) (Action, string, bool) {
+	normalized := Normalize(word)
+	if action, ok := f.words[normalized]; ok {
+		return action, normalized, true
+	}
+	folded := foldLeet(normalized)
+	if action, ok := f.words[folded]; ok {
+		return action, folded, true
+	}
+	return "", "", false
+}
+
+// Normalize case folds a word and strips accents and compatibility forms,
+// so "KÉRFUFFLE" and "ｋｅｒｆｕｆｆｌｅ" both become "kerfuffle".
+func Normalize(word string) string {
+	t := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	stripped, _, err := transform.String(t, strings.TrimSpace(word))
+	if err != nil {
+		stripped = word
+	}
+	return cases.Fold().String(stripped)
+}
+
+func foldLeet(word string) string {
+	return strings.Map(func(r rune) rune {
+		if folded, ok := leetFolds[r]; ok {
+			return folded
+		}
+		return r
+	}, word)
+}
+
+func isWordRune(r rune) bool {
+	if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) {
+		return true
+	}
+	_, ok := leetFolds[r]
+	return ok
+}
+
+// Parse reads a word list with one word per line, optionally followed by an
+// action. Blank lines and lines starting with # are ignored. Words are
+// returned normalized, the same form the filter compares them in.
+func Parse(r io.Reader) (map[string]Action, error) {
+	words := map[string]Action{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		action := Mask
+		if len(fields) > 1 {
+			parsed, err := ParseAction(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			action = parsed
+		}
+		if word := Normalize(fields[0]); word != "" {
+			words[word] = action
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+func LoadFile(path string) (map[string]Action, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// SortedWords returns the list keys in a stable order for API responses.
+func SortedWords(words map[string]Action) []string {
+	keys := make([]string, 0, len(words))
+	for word := range words {
+		keys = append(keys, word)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,117 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+var defaultWords = map[string]Action{
+	"kerfuffle": Mask,
+	"sharbert":  Mask,
+	"fornax":    Mask,
+}
+
+func TestMaskPunctuation(t *testing.T) {
+	f := New(defaultWords)
+	cases := map[string]string{
+		"This is a kerfuffle opinion": "This is a **** opinion",
+		"What a kerfuffle.":           "What a ****.",
+		"KERFUFFLE!":                  "****!",
+		"Sharbert!":                   "****!",
+		"(fornax), kerfuffle's fault": "(****), ****'s fault",
+		"I had something interesting": "I had something interesting",
+	}
+	for input, expected := range cases {
+		actual := f.Check(input).Text
+		if actual != expected {
+			t.Errorf("Check(%q): expected %q, got %q\n", input, expected, actual)
+		}
+	}
+}
+
+func TestMaskUnicodeAndLeet(t *testing.T) {
+	f := New(defaultWords)
+	inputs := []string{
+		"k3rfuffl3",
+		"KÉRFUFFLE",
+		"ｋｅｒｆｕｆｆｌｅ",
+		"f0rn@x",
+		"$harbert",
+	}
+	for _, input := range inputs {
+		result := f.Check(input)
+		if result.Text != maskText {
+			t.Errorf("Check(%q): expected %q, got %q\n", input, maskText, result.Text)
+		}
+		if len(result.Matches) != 1 {
+			t.Errorf("Check(%q): expected one match, got %v\n", input, result.Matches)
+		}
+	}
+}
+
+func TestActionSeverity(t *testing.T) {
+	f := New(map[string]Action{
+		"kerfuffle": Mask,
+		"fornax":    Flag,
+		"sharbert":  Reject,
+	})
+
+	result := f.Check("kerfuffle fornax")
+	if result.Action != Flag || !result.Flagged || result.Rejected {
+		t.Errorf("expected flag action, got %+v\n", result)
+	}
+	if result.Text != "**** fornax" {
+		t.Errorf("flagged words should not be masked, got %q\n", result.Text)
+	}
+
+	result = f.Check("fornax sharbert kerfuffle")
+	if !result.Rejected {
+		t.Errorf("expected reject action, got %+v\n", result)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	f := New(defaultWords)
+	f.Replace(map[string]Action{"Gale": Reject})
+
+	if f.Check("kerfuffle").Text != "kerfuffle" {
+		t.Error("expected replaced word list to drop kerfuffle")
+	}
+	if !f.Check("GALE!").Rejected {
+		t.Error("expected new word to be rejected")
+	}
+	if _, ok := f.Words()["gale"]; !ok {
+		t.Errorf("expected words to be stored normalized, got %v\n", f.Words())
+	}
+}
+
+func TestParse(t *testing.T) {
+	list := `# default words
+Kerfuffle
+sharbert flag
+
+ＦＯＲＮＡＸ REJECT
+`
+	words, err := Parse(strings.NewReader(list))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]Action{
+		"kerfuffle": Mask,
+		"sharbert":  Flag,
+		"fornax":    Reject,
+	}
+	if len(words) != len(expected) {
+		t.Fatalf("expected %d words, got %v\n", len(expected), words)
+	}
+	for word, action := range expected {
+		if words[word] != action {
+			t.Errorf("expected %q to have action %q, got %q\n", word, action, words[word])
+		}
+	}
+
+	_, err = Parse(strings.NewReader("kerfuffle explode"))
+	if err == nil {
+		t.Error("expected an error for an unknown action, got nil")
+	}
+}
@@ -0,0 +1,252 @@
+// Package linkpreview fetches the Open Graph and oEmbed metadata shown on a
+// chirp's link card.
+package linkpreview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Preview is what a page says about itself. Any field may be empty.
+type Preview struct {
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+}
+
+// Fetcher looks up the preview for a URL.
+type Fetcher interface {
+	Fetch(ctx context.Context, rawURL string) (Preview, error)
+}
+
+var (
+	ErrUnsupportedURL = errors.New("only http and https links have previews")
+	ErrNotHTML        = errors.New("link is not an HTML page")
+	ErrNoMetadata     = errors.New("page has no preview metadata")
+)
+
+const (
+	userAgent    = "Chirpy-LinkPreview/1.0"
+	maxRedirects = 5
+	// Longer values are cut off, so a page can't fill a card with an essay.
+	maxTitleLength       = 200
+	maxDescriptionLength = 500
+)
+
+// HTTPFetcher reads metadata from the page itself: Open Graph tags first, then
+// an oEmbed endpoint the page links to, then <title> and the description meta tag.
+type HTTPFetcher struct {
+	Client *http.Client
+	// MaxBytes caps how much of a page or oEmbed response is read.
+	MaxBytes int64
+}
+
+// NewHTTPFetcher returns a fetcher for untrusted URLs: every connection,
+// including redirects, must go to a public address, and each fetch gives up
+// after timeout.
+func NewHTTPFetcher(timeout time.Duration, maxBytes int64) *HTTPFetcher {
+	return &HTTPFetcher{
+		Client: &http.Client{
+			Timeout:   timeout,
+			Transport: PublicTransport(timeout),
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return errors.New("too many redirects")
+				}
+				return checkScheme(req.URL)
+			},
+		},
+		MaxBytes: maxBytes,
+	}
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (Preview, error) {
+	pageURL, err := url.Parse(rawURL)
+	if err != nil {
+		return Preview{}, err
+	}
+	if err := checkScheme(pageURL); err != nil {
+		return Preview{}, err
+	}
+
+	body, finalURL, err := f.get(ctx, pageURL.String(), "text/html", func(mediaType string) bool {
+		return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	})
+	if err != nil {
+		return Preview{}, err
+	}
+	meta := parsePage(body)
+
+	preview := Preview{
+		Title:       firstNonEmpty(meta["og:title"], meta["twitter:title"]),
+		Description: firstNonEmpty(meta["og:description"], meta["twitter:description"]),
+		ImageURL:    firstNonEmpty(meta["og:image"], meta["twitter:image"]),
+		SiteName:    meta["og:site_name"],
+	}
+	if (preview.Title == "" || preview.ImageURL == "") && meta["oembed"] != "" {
+		// A broken oEmbed endpoint shouldn't cost the page its preview.
+		if oembed, err := f.fetchOEmbed(ctx, finalURL, meta["oembed"]); err == nil {
+			preview.Title = firstNonEmpty(preview.Title, oembed.Title)
+			preview.ImageURL = firstNonEmpty(preview.ImageURL, oembed.ThumbnailURL)
+			preview.SiteName = firstNonEmpty(preview.SiteName, oembed.ProviderName)
+		}
+	}
+	preview.Title = firstNonEmpty(preview.Title, meta["title"])
+	preview.Description = firstNonEmpty(preview.Description, meta["description"])
+	if preview.Title == "" && preview.Description == "" && preview.ImageURL == "" {
+		return Preview{}, ErrNoMetadata
+	}
+
+	preview.Title = truncate(preview.Title, maxTitleLength)
+	preview.Description = truncate(preview.Description, maxDescriptionLength)
+	preview.ImageURL = resolve(finalURL, preview.ImageURL)
+	return preview, nil
+}
+
+type oEmbed struct {
+	Title        string `json:"title"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+func (f *HTTPFetcher) fetchOEmbed(ctx context.Context, pageURL *url.URL, href string) (oEmbed, error) {
+	endpoint := resolve(pageURL, href)
+	body, _, err := f.get(ctx, endpoint, "application/json", func(mediaType string) bool {
+		return mediaType == "application/json" || mediaType == "application/json+oembed" || mediaType == "text/javascript"
+	})
+	if err != nil {
+		return oEmbed{}, err
+	}
+	result := oEmbed{}
+	err = json.Unmarshal(body, &result)
+	return result, err
+}
+
+// get reads up to MaxBytes of a 2xx response whose media type passes accept.
+// It returns the body and the URL it was read from after any redirects.
+func (f *HTTPFetcher) get(ctx context.Context, rawURL, acceptHeader string, accept func(string) bool) ([]byte, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", acceptHeader)
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !accept(mediaType) {
+		return nil, nil, ErrNotHTML
+	}
+	// A truncated page still usually has its metadata in the <head>.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.MaxBytes))
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, resp.Request.URL, nil
+}
+
+// parsePage collects the page's <meta> properties and names, its <title> and
+// its oEmbed link, keeping the first value of each.
+func parsePage(body []byte) map[string]string {
+	meta := map[string]string{}
+	set := func(key, value string) {
+		value = strings.TrimSpace(value)
+		if key != "" && value != "" && meta[key] == "" {
+			meta[key] = value
+		}
+	}
+
+	tokens := html.NewTokenizer(strings.NewReader(string(body)))
+	inTitle := false
+	for {
+		switch tokens.Next() {
+		case html.ErrorToken:
+			return meta
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokens.Token()
+			attrs := map[string]string{}
+			for _, a := range token.Attr {
+				attrs[strings.ToLower(a.Key)] = a.Val
+			}
+			switch token.Data {
+			case "meta":
+				key := strings.ToLower(firstNonEmpty(attrs["property"], attrs["name"]))
+				set(key, attrs["content"])
+			case "link":
+				if strings.EqualFold(attrs["type"], "application/json+oembed") {
+					set("oembed", attrs["href"])
+				}
+			case "title":
+				inTitle = true
+			case "body":
+				// Metadata belongs in the <head>.
+				return meta
+			}
+		case html.TextToken:
+			if inTitle {
+				set("title", string(tokens.Text()))
+			}
+		case html.EndTagToken:
+			inTitle = false
+		}
+	}
+}
+
+func checkScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrUnsupportedURL
+	}
+	return nil
+}
+
+// resolve makes ref absolute against base, dropping anything that isn't an
+// http(s) URL.
+func resolve(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	abs := base.ResolveReference(refURL)
+	if checkScheme(abs) != nil {
+		return ""
+	}
+	return abs.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
@@ -0,0 +1,193 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testFetcher talks to httptest servers, which listen on loopback, so it uses
+// the server's client instead of the public-only transport.
+func testFetcher(server *httptest.Server) *HTTPFetcher {
+	return &HTTPFetcher{Client: server.Client(), MaxBytes: 1 << 16}
+}
+
+func TestFetchOpenGraph(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != userAgent {
+			t.Errorf("unexpected user agent %q", r.Header.Get("User-Agent"))
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<!doctype html><html><head>
+			<title>Fallback title</title>
+			<meta property="og:title" content="Los Pollos Hermanos">
+			<meta property="og:description" content=" Taste the family. ">
+			<meta property="og:image" content="/img/chicken.png">
+			<meta property="og:site_name" content="Pollos">
+			</head><body><meta property="og:title" content="Ignored"></body></html>`)
+	}))
+	defer server.Close()
+
+	preview, err := testFetcher(server).Fetch(context.Background(), server.URL+"/menu")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	expected := Preview{
+		Title:       "Los Pollos Hermanos",
+		Description: "Taste the family.",
+		ImageURL:    server.URL + "/img/chicken.png",
+		SiteName:    "Pollos",
+	}
+	if preview != expected {
+		t.Errorf("expected %+v, got %+v", expected, preview)
+	}
+}
+
+func TestFetchOEmbedAndTitleFallback(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/video", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Plain title</title>
+			<meta name="description" content="A video">
+			<link rel="alternate" type="application/json+oembed" href="/oembed?url=video">
+			</head></html>`)
+	})
+	mux.HandleFunc("/oembed", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"title": "oEmbed title", "provider_name": "Tube", "thumbnail_url": "https://img.example.com/t.jpg"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	preview, err := testFetcher(server).Fetch(context.Background(), server.URL+"/video")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	expected := Preview{
+		Title:       "oEmbed title",
+		Description: "A video",
+		ImageURL:    "https://img.example.com/t.jpg",
+		SiteName:    "Tube",
+	}
+	if preview != expected {
+		t.Errorf("expected %+v, got %+v", expected, preview)
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG"))
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head></head><body>nothing</body></html>`)
+	})
+	mux.HandleFunc("/huge", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><head>"+strings.Repeat(" ", 1<<17)+`<title>Too late</title></head></html>`)
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cases := map[string]error{
+		server.URL + "/image": ErrNotHTML,
+		server.URL + "/empty": ErrNoMetadata,
+		server.URL + "/huge":  ErrNoMetadata,
+		"ftp://example.com/":  ErrUnsupportedURL,
+	}
+	for url, expected := range cases {
+		_, err := testFetcher(server).Fetch(context.Background(), url)
+		if !errors.Is(err, expected) {
+			t.Errorf("Fetch(%s): expected %v, got %v", url, expected, err)
+		}
+	}
+	_, err := testFetcher(server).Fetch(context.Background(), server.URL+"/missing")
+	if err == nil {
+		t.Errorf("expected an error for a 404")
+	}
+}
+
+func TestNewHTTPFetcherRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request reached the loopback server")
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPFetcher(time.Second, 1<<16).Fetch(context.Background(), server.URL)
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("expected ErrPrivateAddress, got %v", err)
+	}
+}
+
+func TestNewHTTPFetcherRefusesRedirectsToPrivateAddresses(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request reached the internal server")
+	}))
+	defer internal.Close()
+
+	fetcher := NewHTTPFetcher(time.Second, 1<<16)
+	// Pretend the first hop is public by skipping the dial check for it.
+	redirect := &http.Response{StatusCode: http.StatusFound, Header: http.Header{"Location": {internal.URL}}}
+	transport := fetcher.Client.Transport
+	fetcher.Client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "public.example.com" {
+			redirect.Request = req
+			return redirect, nil
+		}
+		return transport.RoundTrip(req)
+	})
+
+	_, err := fetcher.Fetch(context.Background(), "http://public.example.com/")
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("expected ErrPrivateAddress, got %v", err)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestIsPublic(t *testing.T) {
+	cases := map[string]bool{
+		"93.184.216.34":        true,
+		"2606:4700::1111":      true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"::1":                  false,
+		"fd00::1":              false,
+		"fe80::1":              false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:93.184.216.34": true,
+		"224.0.0.1":            false,
+	}
+	for input, expected := range cases {
+		if got := IsPublic(netip.MustParseAddr(input)); got != expected {
+			t.Errorf("IsPublic(%s) = %v, expected %v", input, got, expected)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("expected short strings to be kept, got %q", got)
+	}
+	if got := truncate("ñandú ñandú", 6); got != "ñandú…" {
+		t.Errorf("expected truncation by runes, got %q", got)
+	}
+}
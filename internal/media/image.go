@@ -0,0 +1,232 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooLarge        = errors.New("image dimensions too large")
+)
+
+// Extensions maps the accepted content types to the extension used for
+// their blob keys.
+var Extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+const (
+	// MaxPixels guards against small files that decode to huge images.
+	MaxPixels   = 40_000_000
+	jpegQuality = 90
+)
+
+// Image is an upload after processing.
+type Image struct {
+	ContentType   string
+	Width         int
+	Height        int
+	Data          []byte
+	Thumbnail     []byte
+	ThumbnailType string
+}
+
+// Process sniffs the content type from the data itself, then decodes and
+// re-encodes the image. Re-encoding drops EXIF and every other metadata
+// block; a JPEG's EXIF orientation is applied first so photos keep facing
+// the right way. The thumbnail fits in a thumbSize square.
+func Process(data []byte, thumbSize int) (Image, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := Extensions[contentType]; !ok {
+		return Image{}, ErrUnsupportedType
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, ErrUnsupportedType
+	}
+	if config.Width*config.Height > MaxPixels {
+		return Image{}, ErrTooLarge
+	}
+
+	result := Image{ContentType: contentType}
+	var img image.Image
+	var out bytes.Buffer
+	switch contentType {
+	case "image/jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return Image{}, ErrUnsupportedType
+		}
+		img = orient(img, jpegOrientation(data))
+		err = jpeg.Encode(&out, img, &jpeg.Options{Quality: jpegQuality})
+	case "image/png":
+		img, err = png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return Image{}, ErrUnsupportedType
+		}
+		err = png.Encode(&out, img)
+	case "image/gif":
+		var anim *gif.GIF
+		anim, err = gif.DecodeAll(bytes.NewReader(data))
+		if err != nil || len(anim.Image) == 0 {
+			return Image{}, ErrUnsupportedType
+		}
+		img = anim.Image[0]
+		// Frames, delays and the loop count survive; comments and
+		// application extensions don't.
+		err = gif.EncodeAll(&out, anim)
+	}
+	if err != nil {
+		return Image{}, err
+	}
+	result.Data = out.Bytes()
+	result.Width = img.Bounds().Dx()
+	result.Height = img.Bounds().Dy()
+
+	thumb := Thumbnail(img, thumbSize)
+	var thumbOut bytes.Buffer
+	if contentType == "image/jpeg" {
+		result.ThumbnailType = "image/jpeg"
+		err = jpeg.Encode(&thumbOut, thumb, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		result.ThumbnailType = "image/png"
+		err = png.Encode(&thumbOut, thumb)
+	}
+	if err != nil {
+		return Image{}, err
+	}
+	result.Thumbnail = thumbOut.Bytes()
+	return result, nil
+}
+
+// Thumbnail scales img down to fit in a size by size square, keeping its
+// aspect ratio. Smaller images are not scaled up.
+func Thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	if w >= h {
+		h = max(1, h*size/w)
+		w = size
+	} else {
+		w = max(1, w*size/h)
+		h = size
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag (1-8) from a JPEG, returning
+// 1 when there isn't one.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// Start of scan or end of image: no more metadata segments.
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return 1
+		}
+		segment := data[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i = end
+	}
+	return 1
+}
+
+// exifOrientation finds tag 0x0112 in the first IFD of a TIFF structure.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value < 1 || value > 8 {
+				return 1
+			}
+			return value
+		}
+	}
+	return 1
+}
+
+// orient applies an EXIF orientation so the image displays upright without it.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,164 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	return img
+}
+
+// withExif inserts an APP1 segment carrying an orientation tag and a fake
+// GPS note right after a JPEG's SOI marker.
+func withExif(t *testing.T, jpg []byte, orientation uint16) []byte {
+	t.Helper()
+	tiff := new(bytes.Buffer)
+	tiff.WriteString("MM")
+	binary.Write(tiff, binary.BigEndian, uint16(42))
+	binary.Write(tiff, binary.BigEndian, uint32(8))
+	binary.Write(tiff, binary.BigEndian, uint16(1))
+	binary.Write(tiff, binary.BigEndian, uint16(0x0112))
+	binary.Write(tiff, binary.BigEndian, uint16(3))
+	binary.Write(tiff, binary.BigEndian, uint32(1))
+	binary.Write(tiff, binary.BigEndian, orientation)
+	binary.Write(tiff, binary.BigEndian, uint16(0))
+	binary.Write(tiff, binary.BigEndian, uint32(0))
+	tiff.WriteString("GPS 51.5074 N")
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	out := new(bytes.Buffer)
+	out.Write(jpg[:2])
+	out.Write([]byte{0xFF, 0xE1})
+	binary.Write(out, binary.BigEndian, uint16(len(segment)+2))
+	out.Write(segment)
+	out.Write(jpg[2:])
+	return out.Bytes()
+}
+
+func TestProcessJPEGStripsExifAndAppliesOrientation(t *testing.T) {
+	plain := new(bytes.Buffer)
+	if err := jpeg.Encode(plain, testImage(40, 20), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := withExif(t, plain.Bytes(), 6)
+	if jpegOrientation(data) != 6 {
+		t.Fatalf("expected to read orientation 6, got %d", jpegOrientation(data))
+	}
+
+	result, err := Process(data, 10)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if result.ContentType != "image/jpeg" {
+		t.Errorf("expected image/jpeg, got %s", result.ContentType)
+	}
+	if bytes.Contains(result.Data, []byte("Exif")) || bytes.Contains(result.Data, []byte("GPS")) {
+		t.Error("processed image still contains EXIF data")
+	}
+	if result.Width != 20 || result.Height != 40 {
+		t.Errorf("expected the rotated size 20x40, got %dx%d", result.Width, result.Height)
+	}
+
+	thumb, err := jpeg.Decode(bytes.NewReader(result.Thumbnail))
+	if err != nil {
+		t.Fatalf("thumbnail isn't a JPEG: %v", err)
+	}
+	if b := thumb.Bounds(); b.Dx() != 5 || b.Dy() != 10 {
+		t.Errorf("expected a 5x10 thumbnail, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestProcessPNG(t *testing.T) {
+	data := new(bytes.Buffer)
+	if err := png.Encode(data, testImage(8, 6)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Process(data.Bytes(), 100)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if result.ContentType != "image/png" || result.ThumbnailType != "image/png" {
+		t.Errorf("unexpected types %s, %s", result.ContentType, result.ThumbnailType)
+	}
+	thumb, err := png.Decode(bytes.NewReader(result.Thumbnail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := thumb.Bounds(); b.Dx() != 8 || b.Dy() != 6 {
+		t.Errorf("small images shouldn't be scaled, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestProcessGIFKeepsFrames(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{
+		Image: []*image.Paletted{
+			image.NewPaletted(image.Rect(0, 0, 4, 4), palette),
+			image.NewPaletted(image.Rect(0, 0, 4, 4), palette),
+		},
+		Delay: []int{10, 10},
+	}
+	data := new(bytes.Buffer)
+	if err := gif.EncodeAll(data, anim); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Process(data.Bytes(), 100)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	decoded, err := gif.DecodeAll(bytes.NewReader(result.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Image) != 2 {
+		t.Errorf("expected 2 frames, got %d", len(decoded.Image))
+	}
+}
+
+func TestProcessRejectsOtherTypes(t *testing.T) {
+	for _, data := range [][]byte{
+		[]byte("just some text"),
+		[]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"),
+		{0xFF, 0xD8, 0xFF, 0xE0, 0x00},
+	} {
+		_, err := Process(data, 100)
+		if !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("expected ErrUnsupportedType for %q, got %v", data, err)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	src := testImage(3, 2)
+	cases := map[int]struct{ x, y, sx, sy int }{
+		2: {0, 0, 2, 0},
+		3: {0, 0, 2, 1},
+		4: {0, 0, 0, 1},
+		5: {1, 0, 0, 1},
+		6: {0, 0, 0, 1},
+		7: {0, 0, 2, 1},
+		8: {0, 0, 2, 0},
+	}
+	for orientation, c := range cases {
+		got := orient(src, orientation).At(c.x, c.y)
+		if got != src.At(c.sx, c.sy) {
+			t.Errorf("orientation %d: pixel (%d,%d) should come from (%d,%d)", orientation, c.x, c.y, c.sx, c.sy)
+		}
+	}
+}
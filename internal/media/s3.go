@@ -0,0 +1,194 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3Config points an S3Store at any S3-compatible service. Objects are
+// addressed path-style, as Endpoint/Bucket/key.
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Client          *http.Client
+}
+
+// S3Store keeps blobs in an S3 bucket, signing requests with AWS Signature
+// Version 4.
+type S3Store struct {
+	endpoint *url.URL
+	config   S3Config
+	now      func() time.Time
+}
+
+const (
+	s3Algorithm   = "AWS4-HMAC-SHA256"
+	s3Service     = "s3"
+	s3DateFormat  = "20060102"
+	s3TimeFormat  = "20060102T150405Z"
+	s3HashHeader  = "X-Amz-Content-Sha256"
+	s3DateHeader  = "X-Amz-Date"
+	s3ErrorBodyAt = 512
+)
+
+func NewS3Store(config S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint: %q", config.Endpoint)
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &S3Store{endpoint: endpoint, config: config, now: time.Now}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return s3Error(res)
+	}
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, ErrNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, s3Error(res)
+	}
+	return res.Body, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return s3Error(res)
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.config.Bucket + "/" + key
+	u.RawPath = uriEncodePath(u.Path)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	payloadHash := sha256.Sum256(body)
+	s.sign(req, hex.EncodeToString(payloadHash[:]))
+	return req, nil
+}
+
+// sign adds the SigV4 Authorization header, covering the host and the
+// x-amz-* headers.
+func (s *S3Store) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	req.Header.Set(s3HashHeader, payloadHash)
+	req.Header.Set(s3DateHeader, now.Format(s3TimeFormat))
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + now.Format(s3TimeFormat),
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := strings.Join([]string{now.Format(s3DateFormat), s.config.Region, s3Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3TimeFormat),
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), now.Format(s3DateFormat))
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncodePath escapes everything but unreserved characters and slashes,
+// as SigV4 expects.
+func uriEncodePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func s3Error(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, s3ErrorBodyAt))
+	return fmt.Errorf("s3 %s %s: %s: %s", res.Request.Method, res.Request.URL.Path, res.Status, strings.TrimSpace(string(body)))
+}
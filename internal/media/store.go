@@ -0,0 +1,18 @@
+// Package media stores uploaded images and prepares them for serving.
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps uploaded files under slash separated keys chosen by the
+// caller. Deleting a missing key is not an error.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,152 @@
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testStore(t *testing.T, store BlobStore) {
+	ctx := context.Background()
+	err := store.Put(ctx, "media/a b.png", []byte("image bytes"), "image/png")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	r, err := store.Get(ctx, "media/a b.png")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "image bytes" {
+		t.Errorf("Get returned %q", data)
+	}
+
+	err = store.Delete(ctx, "media/a b.png")
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	_, err = store.Get(ctx, "media/a b.png")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	err = store.Delete(ctx, "media/a b.png")
+	if err != nil {
+		t.Errorf("deleting a missing blob should succeed, got %v", err)
+	}
+}
+
+func TestFSStore(t *testing.T) {
+	store, err := NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+}
+
+func TestFSStoreRejectsEscapingKeys(t *testing.T) {
+	store, err := NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"../outside", "/etc/passwd", ""} {
+		if err := store.Put(context.Background(), key, []byte("x"), "text/plain"); err == nil {
+			t.Errorf("expected an error for key %q", key)
+		}
+	}
+}
+
+// fakeS3 is a minimal stand-in for an S3-compatible service.
+type fakeS3 struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	hash := sha256.Sum256(body)
+	if r.Header.Get(s3HashHeader) != hex.EncodeToString(hash[:]) {
+		f.t.Errorf("payload hash header doesn't match the body")
+	}
+	auth := r.Header.Get("Authorization")
+	prefix := "AWS4-HMAC-SHA256 Credential=AKID/20250102/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="
+	if !strings.HasPrefix(auth, prefix) || len(auth) != len(prefix)+64 {
+		f.t.Errorf("unexpected Authorization header: %q", auth)
+	}
+	if r.Header.Get(s3DateHeader) != "20250102T030405Z" {
+		f.t.Errorf("unexpected date header: %q", r.Header.Get(s3DateHeader))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[r.URL.Path] = body
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	fake := &fakeS3{t: t, objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:        server.URL,
+		Region:          "eu-west-1",
+		Bucket:          "chirpy",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		Client:          server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+	testStore(t, store)
+}
+
+func TestS3StoreSurfacesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+	}))
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{Endpoint: server.URL, Bucket: "chirpy", Client: server.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Put(context.Background(), "key", []byte("x"), "image/png")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("expected the S3 error body in the error, got %v", err)
+	}
+}
+
+func TestNewS3StoreValidatesConfig(t *testing.T) {
+	if _, err := NewS3Store(S3Config{Endpoint: "not a url", Bucket: "b"}); err == nil {
+		t.Error("expected an error for an invalid endpoint")
+	}
+	if _, err := NewS3Store(S3Config{Endpoint: "http://localhost:9000"}); err == nil {
+		t.Error("expected an error without a bucket")
+	}
+}
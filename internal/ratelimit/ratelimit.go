@@ -0,0 +1,141 @@
+// Package ratelimit implements token bucket rate limits with pluggable
+// storage, so several server instances can share one budget.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Limit allows Requests per Period. Buckets start full, so a client can burst
+// up to Requests at once and then refills steadily over Period.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// perSecond is the bucket's refill rate.
+func (l Limit) perSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Refill returns a bucket's tokens after elapsed time, capped at the limit.
+func (l Limit) Refill(tokens float64, elapsed time.Duration) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(l.Requests), tokens+elapsed.Seconds()*l.perSecond())
+}
+
+// Result describes a bucket after a request has tried to take a token.
+type Result struct {
+	Allowed   bool
+	Limit     Limit
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next token, when the request was refused.
+	RetryAfter time.Duration
+}
+
+// NewResult works out a Result from the tokens left in a bucket.
+func NewResult(limit Limit, tokens float64, allowed bool) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Requests) - tokens) / limit.perSecond()),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / limit.perSecond())
+	}
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
+
+// SetHeaders writes the RateLimit-* headers from the IETF rate limit fields
+// draft, plus Retry-After for refused requests. Times are in whole seconds,
+// rounded up.
+func (r Result) SetHeaders(h http.Header) {
+	h.Set("RateLimit-Limit", strconv.Itoa(r.Limit.Requests))
+	h.Set("RateLimit-Remaining", strconv.Itoa(r.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(r.Reset)))
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", r.Limit.Requests, ceilSeconds(r.Limit.Period)))
+	if !r.Allowed {
+		h.Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(r.RetryAfter))))
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// Store keeps buckets by key and takes one token per call.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryStore keeps buckets in this process.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	takes   int
+	now     func() time.Time
+}
+
+// sweepEvery is how many takes pass between sweeps for full buckets.
+const sweepEvery = 1000
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.takes++
+	if s.takes%sweepEvery == 0 {
+		s.sweep(now, limit.Period)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = limit.Refill(b.tokens, now.Sub(b.updated))
+	b.updated = now
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return NewResult(limit, b.tokens, allowed), nil
+}
+
+// sweep forgets buckets that have had time to refill completely; a new bucket
+// for the same key would start out the same.
+func (s *MemoryStore) sweep(now time.Time, period time.Duration) {
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= period {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func testMemoryStore() (*MemoryStore, *time.Time) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func TestMemoryStoreBurstAndRefill(t *testing.T) {
+	store, now := testMemoryStore()
+	limit := Limit{Requests: 3, Period: time.Minute}
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		result, _ := store.Take(ctx, "user:1", limit)
+		if !result.Allowed || result.Remaining != i {
+			t.Fatalf("expected request to be allowed with %d remaining, got %+v", i, result)
+		}
+	}
+	result, _ := store.Take(ctx, "user:1", limit)
+	if result.Allowed {
+		t.Fatalf("expected the fourth request to be refused")
+	}
+	if result.RetryAfter != 20*time.Second {
+		t.Errorf("expected to retry after 20s, got %v", result.RetryAfter)
+	}
+	if result.Reset != time.Minute {
+		t.Errorf("expected the bucket to be full in 1m, got %v", result.Reset)
+	}
+
+	other, _ := store.Take(ctx, "user:2", limit)
+	if !other.Allowed {
+		t.Errorf("expected a different key to have its own bucket")
+	}
+
+	*now = now.Add(20 * time.Second)
+	result, _ = store.Take(ctx, "user:1", limit)
+	if !result.Allowed || result.Remaining != 0 {
+		t.Errorf("expected one token to have refilled, got %+v", result)
+	}
+
+	*now = now.Add(time.Hour)
+	result, _ = store.Take(ctx, "user:1", limit)
+	if !result.Allowed || result.Remaining != 2 {
+		t.Errorf("expected the bucket to refill no further than the limit, got %+v", result)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store, now := testMemoryStore()
+	limit := Limit{Requests: 10, Period: time.Minute}
+	store.Take(context.Background(), "idle", limit)
+	*now = now.Add(2 * time.Minute)
+	for i := 0; i < sweepEvery; i++ {
+		store.Take(context.Background(), "busy", limit)
+	}
+	if _, ok := store.buckets["idle"]; ok {
+		t.Errorf("expected the idle bucket to be swept")
+	}
+	if _, ok := store.buckets["busy"]; !ok {
+		t.Errorf("expected the busy bucket to be kept")
+	}
+}
+
+func TestSetHeaders(t *testing.T) {
+	limit := Limit{Requests: 60, Period: time.Minute}
+	h := http.Header{}
+	NewResult(limit, 0.5, false).SetHeaders(h)
+	expected := map[string]string{
+		"RateLimit-Limit":     "60",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "60",
+		"RateLimit-Policy":    "60;w=60",
+		"Retry-After":         "1",
+	}
+	for name, value := range expected {
+		if h.Get(name) != value {
+			t.Errorf("%s: expected %q, got %q", name, value, h.Get(name))
+		}
+	}
+
+	h = http.Header{}
+	NewResult(limit, 59, true).SetHeaders(h)
+	if h.Get("Retry-After") != "" || h.Get("RateLimit-Remaining") != "59" || h.Get("RateLimit-Reset") != "1" {
+		t.Errorf("unexpected headers for an allowed request: %v", h)
+	}
+}
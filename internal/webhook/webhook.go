@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/auth"
+)
+
+const (
+	SignatureHeader = "X-Chirpy-Signature"
+	DeliveryHeader  = "X-Chirpy-Delivery-Id"
+	EventHeader     = "X-Chirpy-Event"
+	// MaxAttempts is how many times a delivery is tried before it is dead-lettered.
+	MaxAttempts = 8
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+)
+
+// Backoff returns how long to wait after the given failed attempt (starting
+// at 1): 30s, 1m, 2m, 4m and so on, capped at six hours.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := baseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+// Deliver POSTs a signed JSON body to url. It returns the response status code
+// (0 when no response was received) and an error unless the status is 2xx.
+func Deliver(ctx context.Context, client *http.Client, url, secret, deliveryId, event string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, auth.SignWebhook(secret, body, time.Now()))
+	req.Header.Set(DeliveryHeader, deliveryId)
+	req.Header.Set(EventHeader, event)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/auth"
+)
+
+const secret = "endpoint-secret"
+
+func TestBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		0:  30 * time.Second,
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		5:  8 * time.Minute,
+		20: 6 * time.Hour,
+	}
+	for attempt, expected := range cases {
+		if got := Backoff(attempt); got != expected {
+			t.Errorf("Backoff(%d) = %v, expected %v", attempt, got, expected)
+		}
+	}
+}
+
+func TestDeliverSignsBody(t *testing.T) {
+	body := []byte(`{"event":"chirp.created"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ := io.ReadAll(r.Body)
+		err := auth.VerifyWebhook(secret, r.Header.Get(SignatureHeader), received, time.Minute, time.Now())
+		if err != nil {
+			t.Errorf("invalid signature: %v", err)
+		}
+		if r.Header.Get(DeliveryHeader) != "delivery-1" || r.Header.Get(EventHeader) != "chirp.created" {
+			t.Errorf("missing delivery headers: %v", r.Header)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	status, err := Deliver(context.Background(), server.Client(), server.URL, secret, "delivery-1", "chirp.created", body)
+	if err != nil || status != http.StatusAccepted {
+		t.Errorf("expected 202 without error, got %d: %v", status, err)
+	}
+}
+
+func TestDeliverFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	status, err := Deliver(context.Background(), server.Client(), server.URL, secret, "delivery-1", "chirp.created", []byte(`{}`))
+	if err == nil || status != http.StatusInternalServerError {
+		t.Errorf("expected error with status 500, got %d: %v", status, err)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/chirptext"
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/aramirez3/chirpy/internal/linkpreview"
+)
+
+// LinkPreview is the card shown for the first link in a chirp, once the
+// preview worker has fetched it.
+type LinkPreview struct {
+	Url         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageUrl    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
+
+const (
+	linkPreviewPending = "pending"
+	linkPreviewReady   = "ready"
+	linkPreviewFailed  = "failed"
+
+	linkPreviewInterval    = 5 * time.Second
+	linkPreviewBatchSize   = 10
+	linkPreviewLease       = time.Minute
+	linkPreviewMaxAttempts = 3
+	linkPreviewRetryDelay  = 10 * time.Minute
+	// Cached previews are fetched again when a new chirp links to them after this.
+	linkPreviewTTL = 7 * 24 * time.Hour
+
+	defaultLinkPreviewTimeout  = 5 * time.Second
+	defaultLinkPreviewMaxBytes = 1 << 20
+	maxLinkURLLength           = 2048
+)
+
+// previewLink picks the link a chirp's preview card is for: the first one in
+// the body, or "" when there's none.
+func previewLink(body string) string {
+	for _, link := range chirptext.Links(body) {
+		if len(link) <= maxLinkURLLength {
+			return link
+		}
+	}
+	return ""
+}
+
+// queueLinkPreview asks the worker to fetch a preview for link unless a fresh
+// one is cached. Pass a transaction's queries to queue it with the chirp.
+func queueLinkPreview(ctx context.Context, q *database.Queries, link string) error {
+	if link == "" {
+		return nil
+	}
+	now := time.Now().UTC()
+	return q.QueueLinkPreview(ctx, database.QueueLinkPreviewParams{
+		Url:         link,
+		Now:         now,
+		StaleBefore: now.Add(-linkPreviewTTL),
+	})
+}
+
+func (cfg *apiConfig) runLinkPreviews(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := cfg.fetchDueLinkPreviews(ctx)
+			if err != nil {
+				log.Printf("error fetching link previews: %s\n", err)
+			}
+		}
+	}
+}
+
+func (cfg *apiConfig) fetchDueLinkPreviews(ctx context.Context) error {
+	now := time.Now().UTC()
+	previews, err := cfg.dbQueries.ClaimLinkPreviews(ctx, database.ClaimLinkPreviewsParams{
+		LeaseUntil:  now.Add(linkPreviewLease),
+		Now:         now,
+		MaxPreviews: linkPreviewBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+	for _, preview := range previews {
+		err = cfg.fetchLinkPreview(ctx, preview)
+		if err != nil {
+			log.Printf("error recording link preview for %s: %s\n", preview.Url, err)
+		}
+	}
+	return nil
+}
+
+// fetchLinkPreview makes one attempt at a preview. Errors that a retry won't
+// fix, like a private address or a page without metadata, fail it straight
+// away; others are retried until linkPreviewMaxAttempts.
+func (cfg *apiConfig) fetchLinkPreview(ctx context.Context, preview database.LinkPreview) error {
+	fetchCtx, cancel := context.WithTimeout(ctx, cfg.LinkPreviewTimeout)
+	fetched, fetchErr := cfg.linkPreviews.Fetch(fetchCtx, preview.Url)
+	cancel()
+
+	now := time.Now().UTC()
+	attempts := preview.Attempts + 1
+	if fetchErr == nil {
+		return cfg.dbQueries.SaveLinkPreview(ctx, database.SaveLinkPreviewParams{
+			Url:         preview.Url,
+			Attempts:    attempts,
+			Title:       fetched.Title,
+			Description: fetched.Description,
+			ImageUrl:    fetched.ImageURL,
+			SiteName:    fetched.SiteName,
+			FetchedAt:   sql.NullTime{Time: now, Valid: true},
+		})
+	}
+
+	status := linkPreviewPending
+	if attempts >= linkPreviewMaxAttempts || permanentPreviewError(fetchErr) {
+		status = linkPreviewFailed
+	}
+	return cfg.dbQueries.RecordLinkPreviewFailure(ctx, database.RecordLinkPreviewFailureParams{
+		Url:           preview.Url,
+		Status:        status,
+		Attempts:      attempts,
+		NextAttemptAt: now.Add(time.Duration(attempts) * linkPreviewRetryDelay),
+		LastError:     fetchErr.Error(),
+		UpdatedAt:     now,
+	})
+}
+
+func permanentPreviewError(err error) bool {
+	return errors.Is(err, linkpreview.ErrPrivateAddress) ||
+		errors.Is(err, linkpreview.ErrUnsupportedURL) ||
+		errors.Is(err, linkpreview.ErrNotHTML) ||
+		errors.Is(err, linkpreview.ErrNoMetadata)
+}
+
+// loadChirpPreviews fills in the preview cards of a page of chirps. Chirps
+// whose link hasn't been fetched, or couldn't be, go without a card.
+func (cfg *apiConfig) loadChirpPreviews(ctx context.Context, chirps []Chirp) error {
+	urls := []string{}
+	for _, c := range chirps {
+		if c.Preview != nil {
+			urls = append(urls, c.Preview.Url)
+		}
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+	dbPreviews, err := cfg.dbQueries.GetLinkPreviews(ctx, urls)
+	if err != nil {
+		return err
+	}
+	previews := map[string]database.LinkPreview{}
+	for _, p := range dbPreviews {
+		previews[p.Url] = p
+	}
+	for i := range chirps {
+		if chirps[i].Preview == nil {
+			continue
+		}
+		p, ok := previews[chirps[i].Preview.Url]
+		if !ok {
+			chirps[i].Preview = nil
+			continue
+		}
+		chirps[i].Preview = &LinkPreview{
+			Url:         p.Url,
+			Title:       p.Title,
+			Description: p.Description,
+			ImageUrl:    p.ImageUrl,
+			SiteName:    p.SiteName,
+		}
+	}
+	return nil
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/aramirez3/chirpy/internal/events"
+	"github.com/aramirez3/chirpy/internal/linkpreview"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
@@ -19,6 +25,7 @@ func main() {
 		return
 	}
 	s := createServer("8080")
+	s.Config.db = db
 	s.Config.dbQueries = database.New(db)
 	env, err := godotenv.Read()
 	if err != nil {
@@ -27,5 +34,95 @@ func main() {
 	}
 	s.Config.Secret = env["CHIRPY_SECRET"]
 	s.Config.PolkaKey = env["POLKA_KEY"]
+	if s.Config.PolkaKey == "" {
+		fmt.Println("POLKA_KEY is not set, Polka webhooks will be rejected")
+	}
+	s.Config.AdminKey = env["ADMIN_KEY"]
+	s.Config.Platform = env["PLATFORM"]
+	s.Config.ProfanityFile = env["PROFANITY_FILE"]
+	s.Config.Tiers = map[string]TierLimits{
+		tierFree: {
+			MaxChirpLength:    envInt(env, "CHIRP_MAX_LENGTH", defaultTiers[tierFree].MaxChirpLength),
+			EditWindow:        envDuration(env, "CHIRP_EDIT_WINDOW", defaultTiers[tierFree].EditWindow),
+			RequestsPerMinute: envInt(env, "RATE_LIMIT", defaultTiers[tierFree].RequestsPerMinute),
+			WritesPerMinute:   envInt(env, "WRITE_RATE_LIMIT", defaultTiers[tierFree].WritesPerMinute),
+			MaxPinnedChirps:   envInt(env, "PINNED_CHIRPS_LIMIT", defaultTiers[tierFree].MaxPinnedChirps),
+		},
+		tierRed: {
+			MaxChirpLength:    envInt(env, "CHIRP_MAX_LENGTH_RED", defaultTiers[tierRed].MaxChirpLength),
+			EditWindow:        envDuration(env, "CHIRP_EDIT_WINDOW_RED", defaultTiers[tierRed].EditWindow),
+			RequestsPerMinute: envInt(env, "RATE_LIMIT_RED", defaultTiers[tierRed].RequestsPerMinute),
+			WritesPerMinute:   envInt(env, "WRITE_RATE_LIMIT_RED", defaultTiers[tierRed].WritesPerMinute),
+			MaxPinnedChirps:   envInt(env, "PINNED_CHIRPS_LIMIT_RED", defaultTiers[tierRed].MaxPinnedChirps),
+		},
+	}
+	s.Config.SubscriptionPeriod = envDuration(env, "SUBSCRIPTION_PERIOD", defaultSubscriptionPeriod)
+	s.Config.GracePeriod = envDuration(env, "POLKA_GRACE_PERIOD", defaultGracePeriod)
+	s.Config.PolkaTolerance = envDuration(env, "POLKA_SIGNATURE_TOLERANCE", defaultPolkaTolerance)
+	s.Config.MaxSocketsPerUser = envInt(env, "WS_MAX_CONNECTIONS", defaultMaxSocketsPerUser)
+	if origins := env["WS_ALLOWED_ORIGINS"]; origins != "" {
+		s.Config.SocketOrigins = strings.Split(origins, ",")
+	}
+	s.Config.MaxMediaBytes = envInt(env, "MEDIA_MAX_BYTES", defaultMaxMediaBytes)
+	s.Config.TrashRetention = envDuration(env, "CHIRP_TRASH_RETENTION", defaultTrashRetention)
+	s.Config.LinkPreviewTimeout = envDuration(env, "LINK_PREVIEW_TIMEOUT", defaultLinkPreviewTimeout)
+	s.Config.linkPreviews = linkpreview.NewHTTPFetcher(s.Config.LinkPreviewTimeout, int64(envInt(env, "LINK_PREVIEW_MAX_BYTES", defaultLinkPreviewMaxBytes)))
+	s.Config.AuthRateLimit = envInt(env, "AUTH_RATE_LIMIT", defaultAuthRateLimit)
+	s.Config.TrustProxy = env["RATE_LIMIT_TRUST_PROXY"] == "true"
+	s.Config.rateLimits, err = newRateLimitStore(env["RATE_LIMIT_STORE"], s.Config.dbQueries)
+	if err != nil {
+		fmt.Printf("error configuring rate limits: %s\n", err)
+		return
+	}
+	s.Config.blobs, err = newBlobStore(env)
+	if err != nil {
+		fmt.Printf("error configuring media storage: %s\n", err)
+		return
+	}
+	err = s.Config.loadProfanityFilter(context.Background())
+	if err != nil {
+		fmt.Printf("error loading profanity filter: %s\n", err)
+		return
+	}
+	go s.Config.runSubscriptionExpiry(context.Background(), subscriptionExpiryInterval)
+	go s.Config.runWebhookDeliveries(context.Background(), webhookWorkerInterval)
+	go s.Config.runStreamRetention(context.Background(), streamRetentionInterval)
+	go s.Config.runMediaCleanup(context.Background(), mediaCleanupInterval)
+	go s.Config.runScheduler(context.Background(), schedulerInterval)
+	go s.Config.runTrashPurge(context.Background(), trashPurgeInterval)
+	go s.Config.runLinkPreviews(context.Background(), linkPreviewInterval)
+	go s.Config.runIdempotencyKeyCleanup(context.Background(), idempotencyCleanupInterval)
+	go s.Config.runFilterReload(context.Background(), filterReloadInterval)
+	if env["RATE_LIMIT_STORE"] == rateLimitStorePostgres {
+		go s.Config.runRateLimitCleanup(context.Background(), rateLimitCleanupInterval)
+	}
+	go func() {
+		err := events.ListenPostgres(context.Background(), dbURL, streamChannel, s.Config.eventBus, s.Config.loadStreamEvent)
+		if err != nil {
+			fmt.Printf("error listening for stream events: %s\n", err)
+		}
+	}()
+	go func() {
+		err := events.ListenPostgres(context.Background(), dbURL, userEventsChannel, s.Config.userEventBus, s.Config.loadUserEvent)
+		if err != nil {
+			fmt.Printf("error listening for user events: %s\n", err)
+		}
+	}()
 	s.startServer()
 }
+
+func envInt(env map[string]string, key string, fallback int) int {
+	value, err := strconv.Atoi(env[key])
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func envDuration(env map[string]string, key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(env[key])
+	if err != nil {
+		return fallback
+	}
+	return value
+}
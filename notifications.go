@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type Notification struct {
+	Id        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	Type      string     `json:"type"`
+	ActorId   uuid.UUID  `json:"actor_id"`
+	ChirpId   *uuid.UUID `json:"chirp_id,omitempty"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+}
+
+type UnreadCounts struct {
+	Total  int64            `json:"total"`
+	ByType map[string]int64 `json:"by_type"`
+}
+
+const (
+	notificationFollow  = "follow"
+	notificationMention = "mention"
+	notificationReply   = "reply"
+	notificationLike    = "like"
+	notificationRechirp = "rechirp"
+)
+
+const eventNotificationCreated = "notification.created"
+
+var notificationTypes = map[string]bool{
+	notificationFollow:  true,
+	notificationMention: true,
+	notificationReply:   true,
+	notificationLike:    true,
+	notificationRechirp: true,
+}
+
+// createNotification stores a notification and pushes it to the recipient's
+// sockets. Notifications about your own actions, muted types, and actors the
+// recipient blocked, muted or was blocked by are skipped.
+func createNotification(ctx context.Context, q *database.Queries, recipientId, actorId uuid.UUID, notificationType string, chirpId uuid.NullUUID) error {
+	if recipientId == actorId {
+		return nil
+	}
+	muted, err := q.IsNotificationTypeMuted(ctx, database.IsNotificationTypeMutedParams{
+		UserID: recipientId,
+		Type:   notificationType,
+	})
+	if err != nil || muted {
+		return err
+	}
+	blocked, err := q.IsBlockedEitherWay(ctx, database.IsBlockedEitherWayParams{
+		UserID:  recipientId,
+		OtherID: actorId,
+	})
+	if err != nil || blocked {
+		return err
+	}
+	muted, err = q.IsMuted(ctx, database.IsMutedParams{
+		MuterID: recipientId,
+		MutedID: actorId,
+	})
+	if err != nil || muted {
+		return err
+	}
+
+	dbNotification, err := q.CreateNotification(ctx, database.CreateNotificationParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UserID:    recipientId,
+		ActorID:   actorId,
+		Type:      notificationType,
+		ChirpID:   chirpId,
+	})
+	if err != nil {
+		return err
+	}
+	return recordUserEvent(ctx, q, recipientId, eventNotificationCreated, dbNotificationToResponse(dbNotification))
+}
+
+// notify is createNotification for handlers that aren't in a transaction;
+// failures are logged and don't fail the request.
+func (cfg *apiConfig) notify(ctx context.Context, recipientId, actorId uuid.UUID, notificationType string, chirpId uuid.NullUUID) {
+	err := createNotification(ctx, cfg.dbQueries, recipientId, actorId, notificationType, chirpId)
+	if err != nil {
+		log.Printf("error creating %s notification: %s\n", notificationType, err)
+	}
+}
+
+func (cfg *apiConfig) handleGetNotifications(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	params := database.GetNotificationsParams{
+		UserID:     dbUser.ID,
+		UnreadOnly: req.URL.Query().Get("unread") == "true",
+	}
+	if notificationType := req.URL.Query().Get("type"); notificationType != "" {
+		if !notificationTypes[notificationType] {
+			returnErrorResponse(w, "Unknown notification type")
+			return
+		}
+		params.Type = sql.NullString{String: notificationType, Valid: true}
+	}
+	var err error
+	params.MaxResults, err = queryLimit(req, defaultListLimit, maxListLimit)
+	if err != nil {
+		returnErrorResponse(w, "Invalid limit")
+		return
+	}
+	params.Skip, err = queryOffset(req)
+	if err != nil {
+		returnErrorResponse(w, "Invalid offset")
+		return
+	}
+
+	dbNotifications, err := cfg.dbQueries.GetNotifications(req.Context(), params)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	response := []Notification{}
+	for _, n := range dbNotifications {
+		response = append(response, dbNotificationToResponse(n))
+	}
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleMarkNotificationRead(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	notificationId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	dbNotification, err := cfg.dbQueries.MarkNotificationRead(req.Context(), database.MarkNotificationReadParams{
+		ReadAt: time.Now().UTC(),
+		ID:     notificationId,
+		UserID: dbUser.ID,
+	})
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+
+	respBody, _ := encodeJson(dbNotificationToResponse(dbNotification))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleMarkAllNotificationsRead(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.dbQueries.MarkAllNotificationsRead(req.Context(), database.MarkAllNotificationsReadParams{
+		UserID: dbUser.ID,
+		ReadAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetUnreadCounts(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.GetUnreadNotificationCounts(req.Context(), dbUser.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	counts := UnreadCounts{ByType: map[string]int64{}}
+	for _, row := range rows {
+		counts.ByType[row.Type] = row.Count
+		counts.Total += row.Count
+	}
+
+	respBody, _ := encodeJson(counts)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// handleGetNotificationPreferences reports every notification type as
+// enabled (true) or muted (false).
+func (cfg *apiConfig) handleGetNotificationPreferences(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	cfg.writeNotificationPreferences(w, req, dbUser.ID)
+}
+
+func (cfg *apiConfig) handleUpdateNotificationPreferences(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	payload := map[string]bool{}
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	for notificationType := range payload {
+		if !notificationTypes[notificationType] {
+			returnErrorResponse(w, "Unknown notification type")
+			return
+		}
+	}
+	for notificationType, enabled := range payload {
+		err = cfg.dbQueries.SetNotificationPreference(req.Context(), database.SetNotificationPreferenceParams{
+			UserID:    dbUser.ID,
+			Type:      notificationType,
+			Muted:     !enabled,
+			UpdatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			returnErrorResponse(w, standardError)
+			return
+		}
+	}
+	cfg.writeNotificationPreferences(w, req, dbUser.ID)
+}
+
+func (cfg *apiConfig) writeNotificationPreferences(w http.ResponseWriter, req *http.Request, userId uuid.UUID) {
+	dbPreferences, err := cfg.dbQueries.GetNotificationPreferences(req.Context(), userId)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	preferences := map[string]bool{}
+	for notificationType := range notificationTypes {
+		preferences[notificationType] = true
+	}
+	for _, p := range dbPreferences {
+		preferences[p.Type] = !p.Muted
+	}
+
+	respBody, _ := encodeJson(preferences)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func dbNotificationToResponse(n database.Notification) Notification {
+	response := Notification{
+		Id:        n.ID,
+		CreatedAt: n.CreatedAt,
+		Type:      n.Type,
+		ActorId:   n.ActorID,
+		Read:      n.ReadAt.Valid,
+	}
+	if n.ChirpID.Valid {
+		response.ChirpId = &n.ChirpID.UUID
+	}
+	if n.ReadAt.Valid {
+		response.ReadAt = &n.ReadAt.Time
+	}
+	return response
+}
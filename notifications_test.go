@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestChirpAudience(t *testing.T) {
+	author, parent, mentioned := uuid.New(), uuid.New(), uuid.New()
+
+	notices := chirpAudience(author, parent, []uuid.UUID{parent, mentioned, mentioned, author})
+	expected := []chirpNotice{
+		{userId: parent, notificationType: notificationReply},
+		{userId: mentioned, notificationType: notificationMention},
+	}
+	if !slices.Equal(notices, expected) {
+		t.Errorf("expected %v, got %v", expected, notices)
+	}
+
+	if notices := chirpAudience(author, author, nil); len(notices) != 0 {
+		t.Errorf("expected no notices for replying to yourself, got %v", notices)
+	}
+}
+
+// notificationTypesFor lists the types of the user's notifications, newest first.
+func notificationTypesFor(t *testing.T, url, token string) []string {
+	t.Helper()
+	notifications := []Notification{}
+	status := doRequest(t, http.MethodGet, url+"/api/notifications", token, nil, &notifications)
+	if status != http.StatusOK {
+		t.Fatalf("GET /api/notifications: expected 200, got %d", status)
+	}
+	types := []string{}
+	for _, n := range notifications {
+		types = append(types, n.Type)
+	}
+	return types
+}
+
+func TestRepeatedActionsNotifyOnce(t *testing.T) {
+	s, ts := testDBServer(t)
+	author, authorToken := createTestUser(t, s)
+	_, fanToken := createTestUser(t, s)
+	chirp := postChirp(t, ts, authorToken, ChirpRequest{Body: "like this"})
+
+	for range 2 {
+		doRequest(t, http.MethodPost, ts.URL+"/api/chirps/"+chirp.Id.String()+"/like", fanToken, nil, nil)
+		doRequest(t, http.MethodPost, ts.URL+"/api/users/"+author.ID.String()+"/follow", fanToken, nil, nil)
+	}
+	// A reply that also mentions the author is one reply notification.
+	postChirp(t, ts, fanToken, ChirpRequest{Body: "@" + author.Email + " agreed", ReplyToId: &chirp.Id})
+
+	types := notificationTypesFor(t, ts.URL, authorToken)
+	slices.Sort(types)
+	expected := []string{notificationFollow, notificationLike, notificationReply}
+	if !slices.Equal(types, expected) {
+		t.Errorf("expected %v, got %v", expected, types)
+	}
+}
+
+func TestMutedNotificationTypesAreSkipped(t *testing.T) {
+	s, ts := testDBServer(t)
+	author, authorToken := createTestUser(t, s)
+	_, fanToken := createTestUser(t, s)
+	chirp := postChirp(t, ts, authorToken, ChirpRequest{Body: "like this"})
+
+	preferences := map[string]bool{}
+	status := doRequest(t, http.MethodPut, ts.URL+"/api/notifications/preferences", authorToken, map[string]bool{notificationLike: false}, &preferences)
+	if status != http.StatusOK || preferences[notificationLike] || !preferences[notificationFollow] {
+		t.Fatalf("expected likes muted and follows enabled, got %d %v", status, preferences)
+	}
+
+	doRequest(t, http.MethodPost, ts.URL+"/api/chirps/"+chirp.Id.String()+"/like", fanToken, nil, nil)
+	doRequest(t, http.MethodPost, ts.URL+"/api/users/"+author.ID.String()+"/follow", fanToken, nil, nil)
+
+	if types := notificationTypesFor(t, ts.URL, authorToken); !slices.Equal(types, []string{notificationFollow}) {
+		t.Errorf("expected only the follow notification, got %v", types)
+	}
+}
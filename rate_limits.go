@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/aramirez3/chirpy/internal/ratelimit"
+	"github.com/google/uuid"
+)
+
+const (
+	rateLimitGroupAuth  = "auth"
+	rateLimitGroupWrite = "write"
+	rateLimitGroupRead  = "read"
+
+	rateLimitStoreMemory   = "memory"
+	rateLimitStorePostgres = "postgres"
+
+	rateLimitPeriod          = time.Minute
+	defaultAuthRateLimit     = 10
+	rateLimitCleanupInterval = time.Hour
+	tierCacheTTL             = time.Minute
+	maxTierCacheEntries      = 10000
+)
+
+// authRoutes are limited by IP even for signed in callers, since they're
+// where passwords and refresh tokens get guessed.
+var authRoutes = map[string]bool{
+	"POST /api/users":   true,
+	"POST /api/login":   true,
+	"POST /api/refresh": true,
+	"POST /api/revoke":  true,
+}
+
+// unlimitedRoutes are called by load balancers and Polka, not users.
+var unlimitedRoutes = map[string]bool{
+	"GET /api/healthz":         true,
+	"POST /api/polka/webhooks": true,
+}
+
+// rateLimitGroup sorts a request into the bucket it's counted against, or ""
+// for requests that aren't limited. Only the API is limited.
+func rateLimitGroup(req *http.Request) string {
+	route := req.Method + " " + req.URL.Path
+	switch {
+	case !strings.HasPrefix(req.URL.Path, "/api/") || unlimitedRoutes[route]:
+		return ""
+	case authRoutes[route]:
+		return rateLimitGroupAuth
+	case req.Method == http.MethodGet || req.Method == http.MethodHead:
+		return rateLimitGroupRead
+	default:
+		return rateLimitGroupWrite
+	}
+}
+
+// middlewareRateLimit takes a token from the caller's bucket for the request's
+// group, keyed by user for requests with a valid access token and by IP
+// otherwise, and answers 429 when the bucket is empty.
+func (cfg *apiConfig) middlewareRateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		group := rateLimitGroup(req)
+		if group == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+		key, limit := cfg.rateLimitKey(req, group)
+		result, err := cfg.rateLimits.Take(req.Context(), group+":"+key, limit)
+		if err != nil {
+			// An unavailable store shouldn't take the whole API down with it.
+			log.Printf("error checking rate limit: %s\n", err)
+			next.ServeHTTP(w, req)
+			return
+		}
+		result.SetHeaders(w.Header())
+		if !result.Allowed {
+			returnTooManyRequests(w)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// rateLimitKey picks the bucket for a request and its size. Anonymous callers
+// get the free tier's limits.
+func (cfg *apiConfig) rateLimitKey(req *http.Request, group string) (string, ratelimit.Limit) {
+	limit := ratelimit.Limit{Requests: cfg.AuthRateLimit, Period: rateLimitPeriod}
+	if group != rateLimitGroupAuth {
+		limit.Requests = groupLimit(cfg.tierLimits(tierFree), group)
+		if userId, ok := cfg.bearerUserId(req); ok {
+			tier, err := cfg.cachedUserTier(req.Context(), userId)
+			if err == nil {
+				limit.Requests = groupLimit(cfg.tierLimits(tier), group)
+				return "user:" + userId.String(), limit
+			}
+		}
+	}
+	return "ip:" + clientIP(req, cfg.TrustProxy), limit
+}
+
+func groupLimit(limits TierLimits, group string) int {
+	if group == rateLimitGroupWrite {
+		return limits.WritesPerMinute
+	}
+	return limits.RequestsPerMinute
+}
+
+// clientIP is the address anonymous requests are limited by. Behind a reverse
+// proxy (RATE_LIMIT_TRUST_PROXY) that's the last X-Forwarded-For entry, the
+// address the proxy saw. IPv6 clients usually have a whole /64 to themselves,
+// so they're limited by prefix.
+func clientIP(req *http.Request, trustProxy bool) string {
+	address := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	if forwarded := req.Header.Values("X-Forwarded-For"); trustProxy && len(forwarded) > 0 {
+		hops := strings.Split(forwarded[len(forwarded)-1], ",")
+		address = strings.TrimSpace(hops[len(hops)-1])
+	}
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return address
+	}
+	ip = ip.Unmap()
+	if ip.Is6() {
+		prefix, _ := ip.Prefix(64)
+		return prefix.String()
+	}
+	return ip.String()
+}
+
+// tierCache remembers users' tiers for a short while so the rate limiter
+// doesn't load the user on every request. Upgrades and downgrades take up to
+// tierCacheTTL to change a user's limits.
+type tierCache struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]tierCacheEntry
+}
+
+type tierCacheEntry struct {
+	tier    string
+	expires time.Time
+}
+
+func newTierCache() *tierCache {
+	return &tierCache{entries: map[uuid.UUID]tierCacheEntry{}}
+}
+
+func (cfg *apiConfig) cachedUserTier(ctx context.Context, userId uuid.UUID) (string, error) {
+	cache := cfg.userTiers
+	now := time.Now()
+	cache.mu.Lock()
+	entry, ok := cache.entries[userId]
+	cache.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.tier, nil
+	}
+
+	dbUser, err := cfg.dbQueries.GetUserById(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if len(cache.entries) >= maxTierCacheEntries {
+		for id, e := range cache.entries {
+			if !now.Before(e.expires) {
+				delete(cache.entries, id)
+			}
+		}
+	}
+	cache.entries[userId] = tierCacheEntry{tier: userTier(dbUser), expires: now.Add(tierCacheTTL)}
+	return userTier(dbUser), nil
+}
+
+// newRateLimitStore picks where buckets are kept: in memory, so each instance
+// enforces its own limits, or in Postgres, so every instance shares them.
+func newRateLimitStore(kind string, q *database.Queries) (ratelimit.Store, error) {
+	switch kind {
+	case "", rateLimitStoreMemory:
+		return ratelimit.NewMemoryStore(), nil
+	case rateLimitStorePostgres:
+		return dbRateLimitStore{q: q}, nil
+	default:
+		return nil, fmt.Errorf("unknown RATE_LIMIT_STORE %q", kind)
+	}
+}
+
+type dbRateLimitStore struct {
+	q *database.Queries
+}
+
+func (s dbRateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	row, err := s.q.TakeRateLimitToken(ctx, database.TakeRateLimitTokenParams{
+		Key:       key,
+		Capacity:  float64(limit.Requests),
+		PerSecond: float64(limit.Requests) / limit.Period.Seconds(),
+	})
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	return ratelimit.NewResult(limit, row.Tokens, row.Allowed), nil
+}
+
+// runRateLimitCleanup deletes shared buckets that have been idle long enough
+// to refill; a new bucket for the same key starts out the same.
+func (cfg *apiConfig) runRateLimitCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := cfg.dbQueries.DeleteIdleRateLimitBuckets(ctx, time.Now().UTC().Add(-interval))
+			if err != nil {
+				log.Printf("error deleting idle rate limit buckets: %s\n", err)
+			}
+		}
+	}
+}
@@ -1,13 +1,23 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"sync/atomic"
+	"time"
 
 	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/aramirez3/chirpy/internal/events"
+	"github.com/aramirez3/chirpy/internal/filter"
+	"github.com/aramirez3/chirpy/internal/linkpreview"
+	"github.com/aramirez3/chirpy/internal/media"
+	"github.com/aramirez3/chirpy/internal/ratelimit"
+	"github.com/lib/pq"
 )
 
 type Server struct {
@@ -18,9 +28,39 @@ type Server struct {
 
 type apiConfig struct {
 	fileServerHits atomic.Int32
+	db             *sql.DB
 	dbQueries      *database.Queries
 	Secret         string
 	PolkaKey       string
+	AdminKey       string
+	Platform       string
+	ProfanityFile  string
+	Tiers          map[string]TierLimits
+	profanity      *filter.Filter
+	eventBus       *events.Bus
+	userEventBus   *events.Bus
+	sockets        *socketRegistry
+	// SubscriptionPeriod is used when Polka doesn't send a period_end.
+	SubscriptionPeriod time.Duration
+	GracePeriod        time.Duration
+	// PolkaTolerance is how far a webhook signature timestamp may drift from now.
+	PolkaTolerance    time.Duration
+	MaxSocketsPerUser int
+	// SocketOrigins are the other origins whose pages may open WebSockets.
+	SocketOrigins []string
+	blobs         media.BlobStore
+	MaxMediaBytes int
+	// TrashRetention is how long deleted chirps can be restored.
+	TrashRetention time.Duration
+	linkPreviews   linkpreview.Fetcher
+	// LinkPreviewTimeout bounds each preview fetch, including oEmbed lookups.
+	LinkPreviewTimeout time.Duration
+	rateLimits         ratelimit.Store
+	userTiers          *tierCache
+	// AuthRateLimit is requests per minute per IP to the login and signup routes.
+	AuthRateLimit int
+	// TrustProxy keys anonymous rate limits by X-Forwarded-For.
+	TrustProxy bool
 }
 
 const (
@@ -30,34 +70,142 @@ const (
 	plainTextContentType = "text/plain; charset=utf-8"
 	textHtmlContentType  = "text/html; charset=utf-8"
 	standardError        = "Something went wrong"
+	uniqueViolation      = "23505"
+	defaultListLimit     = 50
+	maxListLimit         = 200
 )
 
 func createServer(port string) *Server {
-	return &Server{http.NewServeMux(), ":" + port, apiConfig{fileServerHits: atomic.Int32{}}}
+	return &Server{http.NewServeMux(), ":" + port, apiConfig{
+		fileServerHits:     atomic.Int32{},
+		eventBus:           events.NewBus(),
+		userEventBus:       events.NewBus(),
+		sockets:            newSocketRegistry(),
+		Tiers:              defaultTiers,
+		profanity:          filter.New(defaultProfaneWords),
+		SubscriptionPeriod: defaultSubscriptionPeriod,
+		GracePeriod:        defaultGracePeriod,
+		PolkaTolerance:     defaultPolkaTolerance,
+		MaxSocketsPerUser:  defaultMaxSocketsPerUser,
+		MaxMediaBytes:      defaultMaxMediaBytes,
+		TrashRetention:     defaultTrashRetention,
+		linkPreviews:       linkpreview.NewHTTPFetcher(defaultLinkPreviewTimeout, defaultLinkPreviewMaxBytes),
+		LinkPreviewTimeout: defaultLinkPreviewTimeout,
+		rateLimits:         ratelimit.NewMemoryStore(),
+		userTiers:          newTierCache(),
+		AuthRateLimit:      defaultAuthRateLimit,
+	}}
 }
 
 func (s *Server) startServer() {
+	handler := s.routes()
+	fmt.Printf("🐣 Chirping on http://localhost%s\n", s.Addr)
+	err := http.ListenAndServe(s.Addr, handler)
+
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+// routes registers every endpoint and returns the server's handler, wrapped in
+// the middleware that applies to all of them.
+func (s *Server) routes() http.Handler {
 	s.Handler.Handle("/app/", http.StripPrefix("/app/", s.Config.middlewareMetricsInc(http.FileServer(serverRootPath))))
 	s.Handler.HandleFunc("GET /api/healthz", handleReadiness)
 	s.Handler.HandleFunc("POST /api/chirps", s.Config.handleNewChirp)
 	s.Handler.HandleFunc("GET /api/chirps", s.Config.handleGetChirps)
 	s.Handler.HandleFunc("GET /api/chirps/{id}", s.Config.handleGetChirp)
+	s.Handler.HandleFunc("PUT /api/chirps/{id}", s.Config.handleEditChirp)
 	s.Handler.HandleFunc("DELETE /api/chirps/{id}", s.Config.handleDeleteChirp)
+	s.Handler.HandleFunc("POST /api/chirps/{id}/restore", s.Config.handleRestoreChirp)
+	s.Handler.HandleFunc("POST /api/chirps/{id}/vote", s.Config.handleVote)
+	s.Handler.HandleFunc("PUT /api/chirps/{id}/bookmark", s.Config.handleBookmarkChirp)
+	s.Handler.HandleFunc("DELETE /api/chirps/{id}/bookmark", s.Config.handleDeleteBookmark)
+	s.Handler.HandleFunc("PUT /api/chirps/{id}/pin", s.Config.handlePinChirp)
+	s.Handler.HandleFunc("DELETE /api/chirps/{id}/pin", s.Config.handleUnpinChirp)
+	s.Handler.HandleFunc("GET /api/scheduled-chirps", s.Config.handleGetScheduledChirps)
+	s.Handler.HandleFunc("GET /api/scheduled-chirps/{id}", s.Config.handleGetScheduledChirp)
+	s.Handler.HandleFunc("PUT /api/scheduled-chirps/{id}", s.Config.handleUpdateScheduledChirp)
+	s.Handler.HandleFunc("DELETE /api/scheduled-chirps/{id}", s.Config.handleDeleteScheduledChirp)
+	s.Handler.HandleFunc("POST /api/media", s.Config.handleUploadMedia)
+	s.Handler.HandleFunc("GET /api/media/{id}", s.Config.handleGetMedia)
+	s.Handler.HandleFunc("GET /api/media/{id}/thumbnail", s.Config.handleGetMediaThumbnail)
+	s.Handler.HandleFunc("GET /api/stream", s.Config.handleStream)
+	s.Handler.HandleFunc("GET /api/ws", s.Config.handleSocket)
+	s.Handler.HandleFunc("POST /api/chirps/{id}/report", s.Config.handleReportChirp)
+	s.Handler.HandleFunc("POST /api/chirps/{id}/like", s.Config.handleLikeChirp)
+	s.Handler.HandleFunc("DELETE /api/chirps/{id}/like", s.Config.handleUnlikeChirp)
+	s.Handler.HandleFunc("POST /api/chirps/{id}/rechirp", s.Config.handleRechirp)
+	s.Handler.HandleFunc("DELETE /api/chirps/{id}/rechirp", s.Config.handleUndoRechirp)
+	s.Handler.HandleFunc("GET /api/moderation/reports", s.Config.handleGetReports)
+	s.Handler.HandleFunc("POST /api/moderation/reports/{id}/claim", s.Config.handleClaimReport)
+	s.Handler.HandleFunc("POST /api/moderation/reports/{id}/resolve", s.Config.handleResolveReport)
+	s.Handler.HandleFunc("GET /api/moderation/actions", s.Config.handleGetModerationActions)
+	s.Handler.HandleFunc("PUT /api/moderation/chirps/{id}/content-warning", s.Config.handleSetContentWarning)
 	s.Handler.HandleFunc("GET /admin/metrics", s.Config.handlerMetrics)
 	s.Handler.HandleFunc("POST /admin/reset", s.Config.handleReset)
+	s.Handler.HandleFunc("GET /admin/filter/words", s.Config.handleGetFilterWords)
+	s.Handler.HandleFunc("PUT /admin/filter/words/{word}", s.Config.handleUpsertFilterWord)
+	s.Handler.HandleFunc("DELETE /admin/filter/words/{word}", s.Config.handleDeleteFilterWord)
+	s.Handler.HandleFunc("GET /admin/webhooks/events", s.Config.handleGetWebhookEvents)
+	s.Handler.HandleFunc("GET /admin/webhooks/events/{id}", s.Config.handleGetWebhookEvent)
+	s.Handler.HandleFunc("POST /admin/webhooks/events/{id}/replay", s.Config.handleReplayWebhookEvent)
+	s.Handler.HandleFunc("POST /admin/users/{id}/suspend", s.Config.handleSuspendUser)
+	s.Handler.HandleFunc("POST /admin/users/{id}/unsuspend", s.Config.handleUnsuspendUser)
+	s.Handler.HandleFunc("POST /admin/users/{id}/ban", s.Config.handleBanUser)
+	s.Handler.HandleFunc("PUT /admin/users/{id}/moderator", s.Config.handleGrantModerator)
+	s.Handler.HandleFunc("DELETE /admin/users/{id}/moderator", s.Config.handleRevokeModerator)
 	s.Handler.HandleFunc("POST /api/users", s.Config.handleNewUser)
 	s.Handler.HandleFunc("PUT /api/users", s.Config.handleUserUpdate)
+	s.Handler.HandleFunc("GET /api/users/me/entitlements", s.Config.handleGetEntitlements)
+	s.Handler.HandleFunc("GET /api/users/me/subscription", s.Config.handleGetSubscription)
+	s.Handler.HandleFunc("POST /api/users/{id}/follow", s.Config.handleFollowUser)
+	s.Handler.HandleFunc("DELETE /api/users/{id}/follow", s.Config.handleUnfollowUser)
+	s.Handler.HandleFunc("GET /api/users/me/privacy", s.Config.handleGetPrivacySettings)
+	s.Handler.HandleFunc("PUT /api/users/me/privacy", s.Config.handleUpdatePrivacySettings)
+	s.Handler.HandleFunc("GET /api/users/me/content-settings", s.Config.handleGetContentSettings)
+	s.Handler.HandleFunc("PUT /api/users/me/content-settings", s.Config.handleUpdateContentSettings)
+	s.Handler.HandleFunc("POST /api/users/{id}/block", s.Config.handleBlockUser)
+	s.Handler.HandleFunc("DELETE /api/users/{id}/block", s.Config.handleUnblockUser)
+	s.Handler.HandleFunc("POST /api/users/{id}/mute", s.Config.handleMuteUser)
+	s.Handler.HandleFunc("DELETE /api/users/{id}/mute", s.Config.handleUnmuteUser)
+	s.Handler.HandleFunc("GET /api/users/me/blocks", s.Config.handleGetBlockedUsers)
+	s.Handler.HandleFunc("GET /api/users/me/mutes", s.Config.handleGetMutedUsers)
+	s.Handler.HandleFunc("GET /api/users/me/trash", s.Config.handleGetTrash)
+	s.Handler.HandleFunc("GET /api/users/me/bookmarks", s.Config.handleGetBookmarks)
+	s.Handler.HandleFunc("POST /api/lists", s.Config.handleCreateList)
+	s.Handler.HandleFunc("GET /api/lists", s.Config.handleGetLists)
+	s.Handler.HandleFunc("PUT /api/lists/{id}", s.Config.handleRenameList)
+	s.Handler.HandleFunc("DELETE /api/lists/{id}", s.Config.handleDeleteList)
+	s.Handler.HandleFunc("GET /api/lists/{id}/chirps", s.Config.handleGetListChirps)
+	s.Handler.HandleFunc("GET /api/lists/{id}/members", s.Config.handleGetListMembers)
+	s.Handler.HandleFunc("PUT /api/lists/{id}/members/{user_id}", s.Config.handleAddListMember)
+	s.Handler.HandleFunc("DELETE /api/lists/{id}/members/{user_id}", s.Config.handleRemoveListMember)
+	s.Handler.HandleFunc("GET /api/users/me/dm-settings", s.Config.handleGetDMSettings)
+	s.Handler.HandleFunc("PUT /api/users/me/dm-settings", s.Config.handleUpdateDMSettings)
+	s.Handler.HandleFunc("POST /api/conversations", s.Config.handleStartConversation)
+	s.Handler.HandleFunc("GET /api/conversations", s.Config.handleGetConversations)
+	s.Handler.HandleFunc("GET /api/conversations/{id}/messages", s.Config.handleGetMessages)
+	s.Handler.HandleFunc("POST /api/conversations/{id}/messages", s.Config.handleSendMessage)
+	s.Handler.HandleFunc("DELETE /api/conversations/{id}/messages/{message_id}", s.Config.handleDeleteMessage)
+	s.Handler.HandleFunc("POST /api/conversations/{id}/read", s.Config.handleMarkConversationRead)
+	s.Handler.HandleFunc("GET /api/notifications", s.Config.handleGetNotifications)
+	s.Handler.HandleFunc("GET /api/notifications/unread-count", s.Config.handleGetUnreadCounts)
+	s.Handler.HandleFunc("GET /api/notifications/preferences", s.Config.handleGetNotificationPreferences)
+	s.Handler.HandleFunc("PUT /api/notifications/preferences", s.Config.handleUpdateNotificationPreferences)
+	s.Handler.HandleFunc("POST /api/notifications/read-all", s.Config.handleMarkAllNotificationsRead)
+	s.Handler.HandleFunc("POST /api/notifications/{id}/read", s.Config.handleMarkNotificationRead)
+	s.Handler.HandleFunc("POST /api/webhooks", s.Config.handleCreateWebhookEndpoint)
+	s.Handler.HandleFunc("GET /api/webhooks", s.Config.handleGetWebhookEndpoints)
+	s.Handler.HandleFunc("DELETE /api/webhooks/{id}", s.Config.handleDeleteWebhookEndpoint)
+	s.Handler.HandleFunc("GET /api/webhooks/{id}/deliveries", s.Config.handleGetWebhookDeliveries)
+	s.Handler.HandleFunc("POST /api/webhooks/{id}/deliveries/{delivery_id}/retry", s.Config.handleRetryWebhookDelivery)
 	s.Handler.HandleFunc("POST /api/login", s.Config.handleLogin)
 	s.Handler.HandleFunc("POST /api/refresh", s.Config.handleRefresh)
 	s.Handler.HandleFunc("POST /api/revoke", s.Config.handleRevoke)
 	s.Handler.HandleFunc("POST /api/polka/webhooks", s.Config.handlePolkaWebhooks)
-	fmt.Printf("🐣 Chirping on http://localhost%s\n", s.Addr)
-	err := http.ListenAndServe(s.Addr, s.Handler)
-
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
+	return s.Config.middlewareRateLimit(s.Config.middlewareIdempotency(s.Handler))
 }
 
 func encodeJson(body any) ([]byte, error) {
@@ -115,3 +263,70 @@ func returnForbidden(w http.ResponseWriter) {
 	})
 	w.Write(respBody)
 }
+
+func returnConflict(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusConflict)
+	w.Header().Add(contentType, plainTextContentType)
+	respBody, _ := encodeJson(ErrorResponse{
+		Error: http.StatusText(http.StatusConflict),
+	})
+	w.Write(respBody)
+}
+
+func returnTooManyRequests(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusTooManyRequests)
+	w.Header().Add(contentType, plainTextContentType)
+	respBody, _ := encodeJson(ErrorResponse{
+		Error: http.StatusText(http.StatusTooManyRequests),
+	})
+	w.Write(respBody)
+}
+
+func returnTooLarge(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	w.Header().Add(contentType, plainTextContentType)
+	respBody, _ := encodeJson(ErrorResponse{
+		Error: http.StatusText(http.StatusRequestEntityTooLarge),
+	})
+	w.Write(respBody)
+}
+
+func returnUnsupportedMediaType(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusUnsupportedMediaType)
+	w.Header().Add(contentType, plainTextContentType)
+	respBody, _ := encodeJson(ErrorResponse{
+		Error: http.StatusText(http.StatusUnsupportedMediaType),
+	})
+	w.Write(respBody)
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// queryLimit reads the optional ?limit= query param, capped at max.
+func queryLimit(req *http.Request, fallback, max int) (int32, error) {
+	limitString := req.URL.Query().Get("limit")
+	if limitString == "" {
+		return int32(fallback), nil
+	}
+	limit, err := strconv.Atoi(limitString)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("invalid limit: %q", limitString)
+	}
+	return int32(min(limit, max)), nil
+}
+
+// queryOffset reads the optional ?offset= query param.
+func queryOffset(req *http.Request) (int32, error) {
+	offsetString := req.URL.Query().Get("offset")
+	if offsetString == "" {
+		return 0, nil
+	}
+	offset, err := strconv.Atoi(offsetString)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid offset: %q", offsetString)
+	}
+	return int32(offset), nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/auth"
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/aramirez3/chirpy/internal/events"
+	"github.com/google/uuid"
+)
+
+const testSecret = "test-secret"
+
+// testServer serves the full route table of a server built by createServer,
+// without a database.
+func testServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	s := createServer("0")
+	ts := httptest.NewServer(s.routes())
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// testDBServer is testServer backed by Postgres: TEST_DATABASE_URL, in a
+// schema of its own with every migration applied. Tests that need it are
+// skipped when TEST_DATABASE_URL isn't set.
+func testDBServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+
+	db, err := sql.Open("postgres", withSearchPath(dbURL, schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrations, _ := filepath.Glob("sql/schema/*.sql")
+	for _, path := range migrations {
+		migration, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		up, _, _ := strings.Cut(string(migration), "-- +goose Down")
+		_, err = db.Exec(up)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+
+	s := createServer("0")
+	s.Config.db = db
+	s.Config.dbQueries = database.New(db)
+	s.Config.Secret = testSecret
+	ts := httptest.NewServer(s.routes())
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// withSearchPath points every connection in the pool at schema; lib/pq sends
+// unknown connection settings to the server as runtime parameters.
+func withSearchPath(dsn, schema string) string {
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		return dsn + " search_path=" + schema
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// createTestUser adds a user straight to the database and returns them with
+// an access token.
+func createTestUser(t *testing.T, s *Server) (database.User, string) {
+	t.Helper()
+	now := time.Now().UTC()
+	dbUser, err := s.Config.dbQueries.CreateUser(context.Background(), database.CreateUserParams{
+		ID:             uuid.New(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Email:          uuid.NewString() + "@example.com",
+		HashedPassword: "unused",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := auth.MakeJWT(dbUser.ID, testSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dbUser, token
+}
+
+// doRequest sends body as JSON with token as the bearer token, either of
+// which may be empty, and decodes a JSON response into out when it's set.
+func doRequest(t *testing.T, method, url, token string, body, out any) int {
+	t.Helper()
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if out != nil && resp.StatusCode < 300 {
+		err = json.Unmarshal(data, out)
+		if err != nil {
+			t.Fatalf("%s %s: decoding %s: %v", method, url, data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// postChirp creates a chirp through the API and fails the test if it's refused.
+func postChirp(t *testing.T, ts *httptest.Server, token string, chirp ChirpRequest) Chirp {
+	t.Helper()
+	created := Chirp{}
+	status := doRequest(t, http.MethodPost, ts.URL+"/api/chirps", token, chirp, &created)
+	if status != http.StatusCreated {
+		t.Fatalf("POST /api/chirps: expected 201, got %d", status)
+	}
+	return created
+}
+
+// chirpIds lists the ids of chirps, for comparing orders.
+func chirpIds(chirps []Chirp) []uuid.UUID {
+	ids := []uuid.UUID{}
+	for _, c := range chirps {
+		ids = append(ids, c.Id)
+	}
+	return ids
+}
+
+// waitFor polls cond until it holds or the test times out.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStreamDeliversPublishedEvents(t *testing.T) {
+	s, ts := testServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/stream", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /api/stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get(contentType); ct != "text/event-stream" {
+		t.Errorf("expected an event stream, got %q", ct)
+	}
+
+	waitFor(t, func() bool { return s.Config.eventBus.Subscribers() == 1 })
+	s.Config.eventBus.Publish(events.Event{ID: 1, Type: "chirp.created", Data: []byte(`{"body":"hi"}`)})
+
+	scanner := bufio.NewScanner(resp.Body)
+	lines := []string{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" && len(lines) > 0 {
+			break
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	expected := "id: 1\nevent: chirp.created\ndata: {\"body\":\"hi\"}"
+	if got := strings.Join(lines, "\n"); got != expected {
+		t.Errorf("expected event %q, got %q", expected, got)
+	}
+}
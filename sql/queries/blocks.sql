@@ -0,0 +1,81 @@
+-- name: BlockUser :execrows
+INSERT INTO blocks(
+    blocker_id,
+    blocked_id,
+    created_at
+)
+    VALUES($1, $2, $3)
+    ON CONFLICT DO NOTHING;
+
+-- name: UnblockUser :execrows
+DELETE FROM blocks
+    WHERE blocker_id=$1 AND blocked_id=$2;
+
+-- name: GetBlockedUsers :many
+SELECT * FROM blocks
+    WHERE blocker_id=$1
+    ORDER BY created_at DESC;
+
+-- name: IsBlockedEitherWay :one
+-- Reports whether either user has blocked the other.
+SELECT EXISTS(
+    SELECT 1 FROM blocks
+        WHERE (blocker_id = sqlc.arg(user_id) AND blocked_id = sqlc.arg(other_id))
+            OR (blocker_id = sqlc.arg(other_id) AND blocked_id = sqlc.arg(user_id))
+);
+
+-- name: IsBlockedByAnyEmail :one
+-- Reports whether any of the users with these (lowercased) emails has
+-- blocked user_id.
+SELECT EXISTS(
+    SELECT 1 FROM blocks
+    JOIN users ON users.id = blocks.blocker_id
+        WHERE blocks.blocked_id = sqlc.arg(user_id)
+            AND lower(users.email) = ANY(sqlc.arg(emails)::text[])
+);
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+    WHERE (follower_id = sqlc.arg(user_id) AND followee_id = sqlc.arg(other_id))
+        OR (follower_id = sqlc.arg(other_id) AND followee_id = sqlc.arg(user_id));
+
+-- name: MuteUser :execrows
+INSERT INTO mutes(
+    muter_id,
+    muted_id,
+    created_at
+)
+    VALUES($1, $2, $3)
+    ON CONFLICT DO NOTHING;
+
+-- name: UnmuteUser :execrows
+DELETE FROM mutes
+    WHERE muter_id=$1 AND muted_id=$2;
+
+-- name: GetMutedUsers :many
+SELECT * FROM mutes
+    WHERE muter_id=$1
+    ORDER BY created_at DESC;
+
+-- name: IsMuted :one
+SELECT EXISTS(
+    SELECT 1 FROM mutes
+        WHERE muter_id=$1 AND muted_id=$2
+);
+
+-- name: GetHiddenAuthorIds :many
+-- Users whose chirps are kept out of viewer_id's feeds: blocked, blocking or muted.
+SELECT blocked_id AS user_id FROM blocks
+    WHERE blocker_id = sqlc.arg(viewer_id)
+UNION
+SELECT blocker_id FROM blocks
+    WHERE blocked_id = sqlc.arg(viewer_id)
+UNION
+SELECT muted_id FROM mutes
+    WHERE muter_id = sqlc.arg(viewer_id);
+
+-- name: DeleteAllBlocks :exec
+DELETE FROM blocks;
+
+-- name: DeleteAllMutes :exec
+DELETE FROM mutes;
@@ -0,0 +1,65 @@
+-- name: BookmarkChirp :execrows
+INSERT INTO bookmarks(
+    user_id,
+    chirp_id,
+    created_at
+)
+    VALUES($1, $2, $3)
+    ON CONFLICT DO NOTHING;
+
+-- name: DeleteBookmark :execrows
+DELETE FROM bookmarks
+    WHERE user_id=$1 AND chirp_id=$2;
+
+-- name: GetBookmarkedChirpsAsc :many
+-- The viewer's bookmarks that they can still read, with the same checks as
+-- GetPublicChirpById.
+SELECT chirps.* FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.id IN (
+        SELECT chirp_id FROM bookmarks WHERE bookmarks.user_id = sqlc.arg(viewer_id)
+    )
+    AND chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = sqlc.arg(viewer_id))
+                OR (blocker_id = sqlc.arg(viewer_id) AND blocked_id = chirps.user_id)
+    )
+    AND (chirps.visibility IN ('public', 'unlisted')
+        OR chirps.user_id = sqlc.arg(viewer_id)
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = sqlc.arg(viewer_id) AND followee_id = chirps.user_id
+        )))
+ORDER BY chirps.created_at ASC;
+
+-- name: GetBookmarkedChirpsDesc :many
+-- The viewer's bookmarks that they can still read, with the same checks as
+-- GetPublicChirpById.
+SELECT chirps.* FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.id IN (
+        SELECT chirp_id FROM bookmarks WHERE bookmarks.user_id = sqlc.arg(viewer_id)
+    )
+    AND chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = sqlc.arg(viewer_id))
+                OR (blocker_id = sqlc.arg(viewer_id) AND blocked_id = chirps.user_id)
+    )
+    AND (chirps.visibility IN ('public', 'unlisted')
+        OR chirps.user_id = sqlc.arg(viewer_id)
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = sqlc.arg(viewer_id) AND followee_id = chirps.user_id
+        )))
+ORDER BY chirps.created_at DESC;
+
+-- name: DeleteAllBookmarks :exec
+DELETE FROM bookmarks;
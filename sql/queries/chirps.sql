@@ -5,9 +5,14 @@ INSERT INTO chirps(
     created_at,
     updated_at,
     body,
-    user_id
+    user_id,
+    reply_to_id,
+    visibility,
+    content_warning,
+    sensitive,
+    link_url
 )
-    VALUES($1, $2, $3, $4, $5)
+    VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
     RETURNING *;
 
 -- name: DeleteAllChirps :exec
@@ -16,30 +21,230 @@ DELETE FROM chirps;
 
 -- name: GetChirpById :one
 SELECT * FROM chirps
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetAnyChirpById :one
+-- Like GetChirpById, but includes chirps in the trash. Only for moderation
+-- and restores.
+SELECT * FROM chirps
 WHERE id = $1;
 
+-- name: GetPublicChirpById :one
+-- Like GetChirpById, but skips hidden chirps, chirps by suspended or banned
+-- authors, chirps by users who blocked or were blocked by viewer_id, and
+-- chirps whose visibility doesn't allow viewer_id to read them.
+SELECT chirps.* FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.id = sqlc.arg(id)
+    AND chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = sqlc.arg(viewer_id))
+                OR (blocker_id = sqlc.arg(viewer_id) AND blocked_id = chirps.user_id)
+    )
+    AND (chirps.visibility IN ('public', 'unlisted')
+        OR chirps.user_id = sqlc.arg(viewer_id)
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = sqlc.arg(viewer_id) AND followee_id = chirps.user_id
+        )));
+
 -- name: GetChirpsCount :one
-SELECT count(*) FROM chirps;
+SELECT count(*) FROM chirps
+WHERE deleted_at IS NULL;
 
 -- name: GetAllChirpsAsc :many
-SELECT * FROM chirps
-ORDER BY created_at ASC;
+SELECT chirps.* FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = sqlc.arg(viewer_id))
+                OR (blocker_id = sqlc.arg(viewer_id) AND blocked_id = chirps.user_id)
+    )
+    AND chirps.visibility <> 'unlisted'
+    AND (chirps.visibility = 'public'
+        OR chirps.user_id = sqlc.arg(viewer_id)
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = sqlc.arg(viewer_id) AND followee_id = chirps.user_id
+        )))
+    AND NOT EXISTS (
+        SELECT 1 FROM mutes
+            WHERE muter_id = sqlc.arg(viewer_id) AND muted_id = chirps.user_id
+    )
+ORDER BY chirps.created_at ASC;
 
 -- name: GetAllChirpsDesc :many
-SELECT * FROM chirps
-ORDER BY created_at DESC;
+SELECT chirps.* FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = sqlc.arg(viewer_id))
+                OR (blocker_id = sqlc.arg(viewer_id) AND blocked_id = chirps.user_id)
+    )
+    AND chirps.visibility <> 'unlisted'
+    AND (chirps.visibility = 'public'
+        OR chirps.user_id = sqlc.arg(viewer_id)
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = sqlc.arg(viewer_id) AND followee_id = chirps.user_id
+        )))
+    AND NOT EXISTS (
+        SELECT 1 FROM mutes
+            WHERE muter_id = sqlc.arg(viewer_id) AND muted_id = chirps.user_id
+    )
+ORDER BY chirps.created_at DESC;
 
 -- name: GetChirpByAuthorIdAsc :many
-SELECT * FROM chirps
-    WHERE user_id=$1
-    ORDER BY created_at ASC;
+SELECT chirps.* FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.user_id = sqlc.arg(user_id)
+    AND chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = sqlc.arg(viewer_id))
+                OR (blocker_id = sqlc.arg(viewer_id) AND blocked_id = chirps.user_id)
+    )
+    AND (chirps.visibility IN ('public', 'unlisted')
+        OR chirps.user_id = sqlc.arg(viewer_id)
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = sqlc.arg(viewer_id) AND followee_id = chirps.user_id
+        )))
+ORDER BY chirps.created_at ASC;
 
 -- name: GetChirpByAuthorIdDesc :many
-SELECT * FROM chirps
-    WHERE user_id=$1
-    ORDER BY created_at DESC;
+SELECT chirps.* FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.user_id = sqlc.arg(user_id)
+    AND chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = sqlc.arg(viewer_id))
+                OR (blocker_id = sqlc.arg(viewer_id) AND blocked_id = chirps.user_id)
+    )
+    AND (chirps.visibility IN ('public', 'unlisted')
+        OR chirps.user_id = sqlc.arg(viewer_id)
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = sqlc.arg(viewer_id) AND followee_id = chirps.user_id
+        )))
+ORDER BY chirps.created_at DESC;
+
+-- name: GetPinnedChirps :many
+SELECT chirps.* FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.user_id = sqlc.arg(user_id)
+    AND chirps.pinned_at IS NOT NULL
+    AND chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = sqlc.arg(viewer_id))
+                OR (blocker_id = sqlc.arg(viewer_id) AND blocked_id = chirps.user_id)
+    )
+    AND (chirps.visibility IN ('public', 'unlisted')
+        OR chirps.user_id = sqlc.arg(viewer_id)
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = sqlc.arg(viewer_id) AND followee_id = chirps.user_id
+        )))
+ORDER BY chirps.pinned_at DESC;
+
+-- name: CountPinnedChirps :one
+SELECT count(*) FROM chirps
+    WHERE user_id=$1 AND pinned_at IS NOT NULL;
+
+-- name: PinChirp :one
+UPDATE chirps
+    SET pinned_at=$2
+    WHERE id=$1 AND deleted_at IS NULL
+    RETURNING *;
+
+-- name: UnpinChirp :exec
+UPDATE chirps
+    SET pinned_at=NULL
+    WHERE id=$1;
 
 -- name: DeleteChirpById :one
 DELETE FROM chirps
     WHERE id=$1
-    RETURNING *;
\ No newline at end of file
+    RETURNING *;
+
+-- name: SoftDeleteChirp :one
+UPDATE chirps
+    SET deleted_at=$2,
+        pinned_at=NULL
+    WHERE id=$1 AND deleted_at IS NULL
+    RETURNING *;
+
+-- name: RestoreChirp :one
+-- Only chirps deleted after deleted_after, i.e. still in the retention
+-- window, can be restored. Chirps removed by a moderator are also hidden and
+-- stay in the trash.
+UPDATE chirps
+    SET deleted_at = NULL
+    WHERE id = sqlc.arg(id)
+        AND user_id = sqlc.arg(user_id)
+        AND deleted_at > sqlc.arg(deleted_after)::timestamp
+        AND hidden_at IS NULL
+    RETURNING *;
+
+-- name: GetTrashedChirps :many
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg(user_id)
+    AND deleted_at > sqlc.arg(deleted_after)::timestamp
+ORDER BY deleted_at DESC
+LIMIT sqlc.arg(max_results)
+OFFSET sqlc.arg(skip);
+
+-- name: PurgeDeletedChirps :execrows
+DELETE FROM chirps
+    WHERE id IN (
+        SELECT id FROM chirps
+            WHERE deleted_at <= sqlc.arg(deleted_before)::timestamp
+            LIMIT sqlc.arg(max_chirps)
+    );
+
+-- name: HideChirp :one
+UPDATE chirps
+    SET hidden_at=$2,
+        updated_at=$3
+    WHERE id=$1
+    RETURNING *;
+
+
+-- name: SetChirpContentWarning :one
+UPDATE chirps
+    SET content_warning=$2,
+        sensitive=$3,
+        updated_at=$4
+    WHERE id=$1
+    RETURNING *;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+    SET body=$2,
+        updated_at=$3,
+        link_url=$4
+    WHERE id=$1
+    RETURNING *;
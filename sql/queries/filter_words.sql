@@ -0,0 +1,23 @@
+-- name: GetFilterWords :many
+SELECT * FROM filter_words
+    ORDER BY word ASC;
+
+-- name: UpsertFilterWord :one
+INSERT INTO filter_words(
+    word,
+    created_at,
+    updated_at,
+    action
+)
+    VALUES($1, $2, $3, $4)
+    ON CONFLICT (word) DO UPDATE
+        SET action = EXCLUDED.action,
+            updated_at = EXCLUDED.updated_at
+    RETURNING *;
+
+-- name: DeleteFilterWord :execrows
+DELETE FROM filter_words
+    WHERE word=$1;
+
+-- name: DeleteAllFilterWords :exec
+DELETE FROM filter_words;
@@ -0,0 +1,48 @@
+-- name: ReserveIdempotencyKey :execrows
+-- Reserves a key for a request about to run. Affects no rows when the key is
+-- already taken, unless it has expired or the request holding it was for the
+-- same payload and never finished.
+INSERT INTO idempotency_keys(
+    scope,
+    key,
+    request_hash,
+    created_at,
+    locked_until
+)
+    VALUES(sqlc.arg(scope), sqlc.arg(key), sqlc.arg(request_hash), sqlc.arg(now), sqlc.arg(locked_until))
+    ON CONFLICT (scope, key) DO UPDATE
+        SET request_hash = EXCLUDED.request_hash,
+            status_code = 0,
+            content_type = '',
+            response_body = '',
+            created_at = EXCLUDED.created_at,
+            locked_until = EXCLUDED.locked_until,
+            completed_at = NULL
+        WHERE idempotency_keys.created_at < sqlc.arg(expired_before)::timestamp
+            OR (idempotency_keys.completed_at IS NULL
+                AND idempotency_keys.locked_until < sqlc.arg(now)::timestamp
+                AND idempotency_keys.request_hash = EXCLUDED.request_hash);
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+    WHERE scope = sqlc.arg(scope) AND key = sqlc.arg(key);
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+    SET status_code = sqlc.arg(status_code),
+        content_type = sqlc.arg(content_type),
+        response_body = sqlc.arg(response_body),
+        completed_at = sqlc.arg(completed_at)
+    WHERE scope = sqlc.arg(scope) AND key = sqlc.arg(key);
+
+-- name: ReleaseIdempotencyKey :exec
+-- Frees a key whose request failed, so a retry runs it again.
+DELETE FROM idempotency_keys
+    WHERE scope = sqlc.arg(scope) AND key = sqlc.arg(key) AND completed_at IS NULL;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+    WHERE created_at < sqlc.arg(expired_before)::timestamp;
+
+-- name: DeleteAllIdempotencyKeys :exec
+DELETE FROM idempotency_keys;
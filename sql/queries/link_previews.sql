@@ -0,0 +1,62 @@
+-- name: QueueLinkPreview :exec
+-- New links are queued for fetching. A cached preview is fetched again once
+-- it's older than stale_before.
+INSERT INTO link_previews(
+    url,
+    created_at,
+    updated_at,
+    next_attempt_at
+)
+    VALUES(sqlc.arg(url), sqlc.arg(now), sqlc.arg(now), sqlc.arg(now))
+    ON CONFLICT (url) DO UPDATE
+        SET status = 'pending',
+            attempts = 0,
+            next_attempt_at = EXCLUDED.next_attempt_at,
+            updated_at = EXCLUDED.updated_at
+        WHERE link_previews.status <> 'pending'
+            AND link_previews.updated_at < sqlc.arg(stale_before)::timestamp;
+
+-- name: ClaimLinkPreviews :many
+-- Claimed previews are leased by pushing next_attempt_at forward, so other
+-- workers skip them while they are being fetched.
+UPDATE link_previews
+    SET next_attempt_at = sqlc.arg(lease_until),
+        updated_at = sqlc.arg(now)
+    WHERE url IN (
+        SELECT url FROM link_previews
+            WHERE status = 'pending' AND next_attempt_at <= sqlc.arg(now)
+            ORDER BY next_attempt_at ASC
+            LIMIT sqlc.arg(max_previews)
+            FOR UPDATE SKIP LOCKED
+    )
+    RETURNING *;
+
+-- name: SaveLinkPreview :exec
+UPDATE link_previews
+    SET status = 'ready',
+        attempts = sqlc.arg(attempts),
+        title = sqlc.arg(title),
+        description = sqlc.arg(description),
+        image_url = sqlc.arg(image_url),
+        site_name = sqlc.arg(site_name),
+        last_error = '',
+        fetched_at = sqlc.arg(fetched_at),
+        updated_at = sqlc.arg(fetched_at)
+    WHERE url = sqlc.arg(url);
+
+-- name: RecordLinkPreviewFailure :exec
+-- A failed refresh keeps the previously fetched preview.
+UPDATE link_previews
+    SET status=$2,
+        attempts=$3,
+        next_attempt_at=$4,
+        last_error=$5,
+        updated_at=$6
+    WHERE url=$1;
+
+-- name: GetLinkPreviews :many
+SELECT * FROM link_previews
+    WHERE url = ANY(sqlc.arg(urls)::text[]) AND fetched_at IS NOT NULL;
+
+-- name: DeleteAllLinkPreviews :exec
+DELETE FROM link_previews;
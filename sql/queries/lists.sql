@@ -0,0 +1,102 @@
+-- name: CreateList :one
+INSERT INTO lists(id, created_at, updated_at, user_id, name)
+    VALUES($1, $2, $3, $4, $5)
+    RETURNING *;
+
+-- name: GetList :one
+SELECT * FROM lists
+    WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: GetListsForUser :many
+SELECT * FROM lists
+    WHERE user_id=$1
+    ORDER BY name;
+
+-- name: RenameList :one
+UPDATE lists
+    SET name = sqlc.arg(name),
+        updated_at = sqlc.arg(updated_at)
+    WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+    RETURNING *;
+
+-- name: DeleteList :execrows
+DELETE FROM lists
+    WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: AddListMember :execrows
+INSERT INTO list_members(list_id, user_id, created_at)
+    VALUES($1, $2, $3)
+    ON CONFLICT DO NOTHING;
+
+-- name: RemoveListMember :execrows
+DELETE FROM list_members
+    WHERE list_id=$1 AND user_id=$2;
+
+-- name: GetListMembers :many
+SELECT * FROM list_members
+    WHERE list_id=$1
+    ORDER BY created_at DESC;
+
+-- name: GetListChirpsAsc :many
+-- Chirps by the list's members, filtered like GetAllChirps for the viewer.
+SELECT chirps.* FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.user_id IN (
+        SELECT list_members.user_id FROM list_members WHERE list_members.list_id = sqlc.arg(list_id)
+    )
+    AND chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = sqlc.arg(viewer_id))
+                OR (blocker_id = sqlc.arg(viewer_id) AND blocked_id = chirps.user_id)
+    )
+    AND chirps.visibility <> 'unlisted'
+    AND (chirps.visibility = 'public'
+        OR chirps.user_id = sqlc.arg(viewer_id)
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = sqlc.arg(viewer_id) AND followee_id = chirps.user_id
+        )))
+    AND NOT EXISTS (
+        SELECT 1 FROM mutes
+            WHERE muter_id = sqlc.arg(viewer_id) AND muted_id = chirps.user_id
+    )
+ORDER BY chirps.created_at ASC;
+
+-- name: GetListChirpsDesc :many
+-- Chirps by the list's members, filtered like GetAllChirps for the viewer.
+SELECT chirps.* FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.user_id IN (
+        SELECT list_members.user_id FROM list_members WHERE list_members.list_id = sqlc.arg(list_id)
+    )
+    AND chirps.hidden_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND users.banned_at IS NULL
+    AND (users.suspended_until IS NULL OR users.suspended_until < (now() AT TIME ZONE 'utc'))
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+            WHERE (blocker_id = chirps.user_id AND blocked_id = sqlc.arg(viewer_id))
+                OR (blocker_id = sqlc.arg(viewer_id) AND blocked_id = chirps.user_id)
+    )
+    AND chirps.visibility <> 'unlisted'
+    AND (chirps.visibility = 'public'
+        OR chirps.user_id = sqlc.arg(viewer_id)
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+                WHERE follower_id = sqlc.arg(viewer_id) AND followee_id = chirps.user_id
+        )))
+    AND NOT EXISTS (
+        SELECT 1 FROM mutes
+            WHERE muter_id = sqlc.arg(viewer_id) AND muted_id = chirps.user_id
+    )
+ORDER BY chirps.created_at DESC;
+
+-- name: DeleteAllListMembers :exec
+DELETE FROM list_members;
+
+-- name: DeleteAllLists :exec
+DELETE FROM lists;
@@ -0,0 +1,53 @@
+-- name: CreateMedia :one
+INSERT INTO media(
+    id,
+    created_at,
+    user_id,
+    content_type,
+    size_bytes,
+    width,
+    height,
+    storage_key,
+    thumbnail_key,
+    thumbnail_type
+)
+    VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    RETURNING *;
+
+-- name: GetMediaById :one
+SELECT * FROM media
+    WHERE id=$1;
+
+-- name: AttachMedia :one
+-- Attaches an upload to a chirp; uploads that belong to someone else or are
+-- already attached aren't returned.
+UPDATE media
+    SET chirp_id = sqlc.arg(chirp_id),
+        position = sqlc.arg(position)
+    WHERE id = sqlc.arg(id)
+        AND user_id = sqlc.arg(user_id)
+        AND chirp_id IS NULL
+    RETURNING *;
+
+-- name: GetMediaForChirps :many
+SELECT * FROM media
+    WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+    ORDER BY chirp_id, position;
+
+-- name: GetOrphanedMedia :many
+-- Uploads waiting on a scheduled chirp or draft aren't orphaned.
+SELECT * FROM media
+    WHERE chirp_id IS NULL AND created_at < $1
+        AND NOT EXISTS (
+            SELECT 1 FROM scheduled_chirps
+                WHERE media.id = ANY(scheduled_chirps.media_ids)
+        )
+    ORDER BY created_at
+    LIMIT $2;
+
+-- name: DeleteMediaById :exec
+DELETE FROM media
+    WHERE id=$1;
+
+-- name: DeleteAllMedia :exec
+DELETE FROM media;
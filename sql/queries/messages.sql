@@ -0,0 +1,84 @@
+-- name: CreateConversation :one
+INSERT INTO conversations(
+    id,
+    created_at,
+    updated_at,
+    user_one_id,
+    user_two_id
+)
+    VALUES($1, $2, $3, $4, $5)
+    ON CONFLICT (user_one_id, user_two_id)
+        DO UPDATE SET user_one_id = excluded.user_one_id
+    RETURNING *;
+
+-- name: GetConversationById :one
+SELECT * FROM conversations
+    WHERE id=$1;
+
+-- name: GetConversationsForUser :many
+SELECT * FROM conversations
+    WHERE user_one_id = sqlc.arg(user_id) OR user_two_id = sqlc.arg(user_id)
+    ORDER BY updated_at DESC
+    LIMIT sqlc.arg(max_results)
+    OFFSET sqlc.arg(skip);
+
+-- name: TouchConversation :exec
+UPDATE conversations
+    SET updated_at=$2
+    WHERE id=$1;
+
+-- name: CreateMessage :one
+INSERT INTO messages(
+    id,
+    created_at,
+    conversation_id,
+    sender_id,
+    body
+)
+    VALUES($1, $2, $3, $4, $5)
+    RETURNING *;
+
+-- name: GetMessages :many
+SELECT * FROM messages
+    WHERE conversation_id = sqlc.arg(conversation_id)
+    ORDER BY created_at DESC
+    LIMIT sqlc.arg(max_results)
+    OFFSET sqlc.arg(skip);
+
+-- name: GetMessageById :one
+SELECT * FROM messages
+    WHERE id=$1;
+
+-- name: DeleteMessage :exec
+DELETE FROM messages
+    WHERE id=$1;
+
+-- name: MarkConversationRead :execrows
+-- Marks the messages the other participant sent as read by reader_id.
+UPDATE messages
+    SET read_at = sqlc.arg(read_at)
+    WHERE conversation_id = sqlc.arg(conversation_id)
+        AND sender_id <> sqlc.arg(reader_id)
+        AND read_at IS NULL;
+
+-- name: GetUnreadMessageCounts :many
+SELECT conversation_id, count(*) FROM messages
+    WHERE conversation_id IN (
+            SELECT id FROM conversations
+                WHERE user_one_id = sqlc.arg(user_id) OR user_two_id = sqlc.arg(user_id)
+        )
+        AND sender_id <> sqlc.arg(user_id)
+        AND read_at IS NULL
+    GROUP BY conversation_id;
+
+-- name: DeleteAllMessages :exec
+DELETE FROM messages;
+
+-- name: DeleteAllConversations :exec
+DELETE FROM conversations;
+
+-- name: HasSentMessage :one
+SELECT EXISTS(
+    SELECT 1 FROM messages
+        WHERE conversation_id=$1 AND sender_id=$2
+);
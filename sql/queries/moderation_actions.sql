@@ -0,0 +1,20 @@
+-- name: CreateModerationAction :one
+INSERT INTO moderation_actions(
+    id,
+    created_at,
+    moderator_id,
+    action,
+    report_id,
+    chirp_id,
+    user_id,
+    notes
+)
+    VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+    RETURNING *;
+
+-- name: GetModerationActions :many
+SELECT * FROM moderation_actions
+    ORDER BY created_at DESC;
+
+-- name: DeleteAllModerationActions :exec
+DELETE FROM moderation_actions;
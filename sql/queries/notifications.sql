@@ -0,0 +1,64 @@
+-- name: CreateNotification :one
+INSERT INTO notifications(
+    id,
+    created_at,
+    user_id,
+    actor_id,
+    type,
+    chirp_id
+)
+    VALUES($1, $2, $3, $4, $5, $6)
+    RETURNING *;
+
+-- name: GetNotifications :many
+SELECT * FROM notifications
+    WHERE user_id = sqlc.arg(user_id)
+        AND (sqlc.narg(type)::text IS NULL OR type = sqlc.narg(type)::text)
+        AND (NOT sqlc.arg(unread_only)::boolean OR read_at IS NULL)
+    ORDER BY created_at DESC
+    LIMIT sqlc.arg(max_results)
+    OFFSET sqlc.arg(skip);
+
+-- name: MarkNotificationRead :one
+UPDATE notifications
+    SET read_at = COALESCE(read_at, sqlc.arg(read_at)::timestamp)
+    WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+    RETURNING *;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+    SET read_at=$2
+    WHERE user_id=$1 AND read_at IS NULL;
+
+-- name: GetUnreadNotificationCounts :many
+SELECT type, count(*) FROM notifications
+    WHERE user_id=$1 AND read_at IS NULL
+    GROUP BY type;
+
+-- name: DeleteAllNotifications :exec
+DELETE FROM notifications;
+
+-- name: GetNotificationPreferences :many
+SELECT * FROM notification_preferences
+    WHERE user_id=$1;
+
+-- name: IsNotificationTypeMuted :one
+SELECT EXISTS(
+    SELECT 1 FROM notification_preferences
+        WHERE user_id=$1 AND type=$2 AND muted
+);
+
+-- name: SetNotificationPreference :exec
+INSERT INTO notification_preferences(
+    user_id,
+    type,
+    muted,
+    updated_at
+)
+    VALUES($1, $2, $3, $4)
+    ON CONFLICT (user_id, type) DO UPDATE
+        SET muted = EXCLUDED.muted,
+            updated_at = EXCLUDED.updated_at;
+
+-- name: DeleteAllNotificationPreferences :exec
+DELETE FROM notification_preferences;
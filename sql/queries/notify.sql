@@ -0,0 +1,3 @@
+-- name: NotifyChannel :exec
+-- The notification is only sent once the surrounding transaction commits.
+SELECT pg_notify(sqlc.arg(channel)::text, sqlc.arg(payload)::text);
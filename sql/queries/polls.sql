@@ -0,0 +1,52 @@
+-- name: CreatePoll :one
+INSERT INTO polls(chirp_id, closes_at)
+    VALUES($1, $2)
+    RETURNING *;
+
+-- name: CreatePollOption :one
+INSERT INTO poll_options(id, chirp_id, position, label)
+    VALUES($1, $2, $3, $4)
+    RETURNING *;
+
+-- name: GetPollByChirpId :one
+SELECT * FROM polls
+    WHERE chirp_id=$1;
+
+-- name: GetPollOption :one
+SELECT * FROM poll_options
+    WHERE id = sqlc.arg(id) AND chirp_id = sqlc.arg(chirp_id);
+
+-- name: GetPollsForChirps :many
+SELECT * FROM polls
+    WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[]);
+
+-- name: GetPollOptionsForChirps :many
+SELECT * FROM poll_options
+    WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+    ORDER BY chirp_id, position;
+
+-- name: GetPollVoteCounts :many
+SELECT option_id, count(*) FROM poll_votes
+    WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+    GROUP BY option_id;
+
+-- name: GetPollVotesByUser :many
+SELECT * FROM poll_votes
+    WHERE user_id = sqlc.arg(user_id)
+        AND chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[]);
+
+-- name: CreatePollVote :one
+-- Returns no rows when the user already voted.
+INSERT INTO poll_votes(chirp_id, user_id, option_id, created_at)
+    VALUES($1, $2, $3, $4)
+    ON CONFLICT (chirp_id, user_id) DO NOTHING
+    RETURNING *;
+
+-- name: DeleteAllPollVotes :exec
+DELETE FROM poll_votes;
+
+-- name: DeleteAllPollOptions :exec
+DELETE FROM poll_options;
+
+-- name: DeleteAllPolls :exec
+DELETE FROM polls;
@@ -0,0 +1,26 @@
+-- name: TakeRateLimitToken :one
+-- Refills the bucket for the time since it was last used, then takes a token
+-- if there's one left, all in one statement so concurrent requests from any
+-- instance can't overspend. The database clock is used so instances don't
+-- need to agree on the time.
+INSERT INTO rate_limit_buckets(
+    key,
+    tokens,
+    allowed,
+    updated_at
+)
+    VALUES(sqlc.arg(key), sqlc.arg(capacity)::float8 - 1, true, now() AT TIME ZONE 'utc')
+    ON CONFLICT (key) DO UPDATE
+        -- Both expressions see the bucket as it was before this request.
+        SET tokens = LEAST(sqlc.arg(capacity)::float8, rate_limit_buckets.tokens + EXTRACT(EPOCH FROM (now() AT TIME ZONE 'utc') - rate_limit_buckets.updated_at)::float8 * sqlc.arg(per_second)::float8)
+                - (LEAST(sqlc.arg(capacity)::float8, rate_limit_buckets.tokens + EXTRACT(EPOCH FROM (now() AT TIME ZONE 'utc') - rate_limit_buckets.updated_at)::float8 * sqlc.arg(per_second)::float8) >= 1)::int,
+            allowed = LEAST(sqlc.arg(capacity)::float8, rate_limit_buckets.tokens + EXTRACT(EPOCH FROM (now() AT TIME ZONE 'utc') - rate_limit_buckets.updated_at)::float8 * sqlc.arg(per_second)::float8) >= 1,
+            updated_at = now() AT TIME ZONE 'utc'
+    RETURNING tokens, allowed;
+
+-- name: DeleteIdleRateLimitBuckets :execrows
+DELETE FROM rate_limit_buckets
+    WHERE updated_at < sqlc.arg(idle_before)::timestamp;
+
+-- name: DeleteAllRateLimitBuckets :exec
+DELETE FROM rate_limit_buckets;
@@ -19,4 +19,13 @@ UPDATE refresh_tokens
     SET updated_at = $2,
         revoked_at = $3
     WHERE token = $1
-    RETURNING *;
\ No newline at end of file
+    RETURNING *;
+
+-- name: RevokeUserRefreshTokens :exec
+UPDATE refresh_tokens
+    SET updated_at = $2,
+        revoked_at = $3
+    WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: DeleteAllRefreshTokens :exec
+DELETE FROM refresh_tokens;
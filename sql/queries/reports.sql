@@ -0,0 +1,51 @@
+-- name: CreateReport :one
+INSERT INTO reports(
+    id,
+    created_at,
+    updated_at,
+    chirp_id,
+    reporter_id,
+    reason,
+    details
+)
+    VALUES($1, $2, $3, $4, $5, $6, $7)
+    RETURNING *;
+
+-- name: GetReportById :one
+SELECT * FROM reports
+    WHERE id=$1;
+
+-- name: GetReportsByStatus :many
+SELECT * FROM reports
+    WHERE status=$1
+    ORDER BY created_at ASC;
+
+-- name: ClaimReport :one
+UPDATE reports
+    SET status='claimed',
+        moderator_id=$2,
+        updated_at=$3
+    WHERE id=$1 AND status='open'
+    RETURNING *;
+
+-- name: ResolveReport :one
+-- Only the moderator holding the claim can resolve it, once.
+UPDATE reports
+    SET status='resolved',
+        resolution=$2,
+        resolved_at=$3,
+        updated_at=$4
+    WHERE id=$1 AND status='claimed' AND moderator_id=$5
+    RETURNING *;
+
+-- name: ResolveOpenReportsByChirpId :exec
+UPDATE reports
+    SET status='resolved',
+        moderator_id=$2,
+        resolution=$3,
+        resolved_at=$4,
+        updated_at=$5
+    WHERE chirp_id=$1 AND status<>'resolved';
+
+-- name: DeleteAllReports :exec
+DELETE FROM reports;
@@ -0,0 +1,72 @@
+-- name: CreateScheduledChirp :one
+INSERT INTO scheduled_chirps(
+    id,
+    created_at,
+    updated_at,
+    user_id,
+    body,
+    reply_to_id,
+    visibility,
+    media_ids,
+    status,
+    publish_at,
+    content_warning,
+    sensitive
+)
+    VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+    RETURNING *;
+
+-- name: GetScheduledChirp :one
+SELECT * FROM scheduled_chirps
+    WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: GetScheduledChirpsForUser :many
+SELECT * FROM scheduled_chirps
+    WHERE user_id = sqlc.arg(user_id)
+        AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+    ORDER BY publish_at ASC NULLS LAST, created_at DESC
+    LIMIT sqlc.arg(max_results)
+    OFFSET sqlc.arg(skip);
+
+-- name: UpdateScheduledChirp :one
+-- Editing clears a failure, so a failed chirp can be fixed and rescheduled.
+UPDATE scheduled_chirps
+    SET body = sqlc.arg(body),
+        reply_to_id = sqlc.arg(reply_to_id),
+        visibility = sqlc.arg(visibility),
+        media_ids = sqlc.arg(media_ids),
+        status = sqlc.arg(status),
+        publish_at = sqlc.arg(publish_at),
+        content_warning = sqlc.arg(content_warning),
+        sensitive = sqlc.arg(sensitive),
+        failure_reason = '',
+        updated_at = sqlc.arg(updated_at)
+    WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+    RETURNING *;
+
+-- name: DeleteScheduledChirp :execrows
+DELETE FROM scheduled_chirps
+    WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: ClaimDueScheduledChirp :one
+-- Locks one due chirp. Other instances skip it until the publishing
+-- transaction commits and the row is gone.
+SELECT * FROM scheduled_chirps
+    WHERE status = 'scheduled' AND publish_at <= sqlc.arg(now)::timestamp
+    ORDER BY publish_at ASC
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED;
+
+-- name: DeletePublishedScheduledChirp :exec
+DELETE FROM scheduled_chirps
+    WHERE id=$1;
+
+-- name: FailScheduledChirp :exec
+UPDATE scheduled_chirps
+    SET status = 'failed',
+        failure_reason = sqlc.arg(failure_reason),
+        updated_at = sqlc.arg(updated_at)
+    WHERE id = sqlc.arg(id);
+
+-- name: DeleteAllScheduledChirps :exec
+DELETE FROM scheduled_chirps;
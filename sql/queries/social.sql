@@ -0,0 +1,53 @@
+-- name: FollowUser :execrows
+INSERT INTO follows(
+    follower_id,
+    followee_id,
+    created_at
+)
+    VALUES($1, $2, $3)
+    ON CONFLICT DO NOTHING;
+
+-- name: UnfollowUser :execrows
+DELETE FROM follows
+    WHERE follower_id=$1 AND followee_id=$2;
+
+-- name: IsFollowing :one
+SELECT EXISTS(
+    SELECT 1 FROM follows
+        WHERE follower_id=$1 AND followee_id=$2
+);
+
+-- name: DeleteAllFollows :exec
+DELETE FROM follows;
+
+-- name: LikeChirp :execrows
+INSERT INTO likes(
+    user_id,
+    chirp_id,
+    created_at
+)
+    VALUES($1, $2, $3)
+    ON CONFLICT DO NOTHING;
+
+-- name: UnlikeChirp :execrows
+DELETE FROM likes
+    WHERE user_id=$1 AND chirp_id=$2;
+
+-- name: DeleteAllLikes :exec
+DELETE FROM likes;
+
+-- name: Rechirp :execrows
+INSERT INTO rechirps(
+    user_id,
+    chirp_id,
+    created_at
+)
+    VALUES($1, $2, $3)
+    ON CONFLICT DO NOTHING;
+
+-- name: UndoRechirp :execrows
+DELETE FROM rechirps
+    WHERE user_id=$1 AND chirp_id=$2;
+
+-- name: DeleteAllRechirps :exec
+DELETE FROM rechirps;
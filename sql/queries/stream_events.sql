@@ -0,0 +1,25 @@
+-- name: CreateStreamEvent :one
+INSERT INTO stream_events(
+    created_at,
+    type,
+    payload
+)
+    VALUES($1, $2, $3)
+    RETURNING *;
+
+-- name: GetStreamEventById :one
+SELECT * FROM stream_events
+    WHERE id=$1;
+
+-- name: GetStreamEventsAfter :many
+SELECT * FROM stream_events
+    WHERE id > $1
+    ORDER BY id ASC
+    LIMIT $2;
+
+-- name: DeleteStreamEventsBefore :exec
+DELETE FROM stream_events
+    WHERE created_at < $1;
+
+-- name: DeleteAllStreamEvents :exec
+DELETE FROM stream_events;
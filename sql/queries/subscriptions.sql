@@ -0,0 +1,64 @@
+-- name: UpsertSubscription :one
+INSERT INTO subscriptions(
+    id,
+    created_at,
+    updated_at,
+    user_id,
+    status,
+    started_at,
+    current_period_end
+)
+    VALUES($1, $2, $3, $4, $5, $6, $7)
+    ON CONFLICT (user_id) DO UPDATE
+        SET status = EXCLUDED.status,
+            started_at = EXCLUDED.started_at,
+            current_period_end = EXCLUDED.current_period_end,
+            grace_until = NULL,
+            ended_at = NULL,
+            updated_at = EXCLUDED.updated_at
+    RETURNING *;
+
+-- name: GetSubscriptionByUserId :one
+SELECT * FROM subscriptions
+    WHERE user_id=$1;
+
+-- name: UpdateSubscription :one
+UPDATE subscriptions
+    SET status=$2,
+        current_period_end=$3,
+        grace_until=$4,
+        ended_at=$5,
+        updated_at=$6
+    WHERE id=$1
+    RETURNING *;
+
+-- name: GetLapsedSubscriptions :many
+-- Active subscriptions get the grace period after their period ends, past due
+-- ones keep Chirpy Red until grace_until and cancelled ones until the period ends.
+SELECT * FROM subscriptions
+    WHERE (status = 'active' AND current_period_end < sqlc.arg(active_lapsed_before))
+        OR (status = 'cancelled' AND current_period_end < sqlc.arg(now))
+        OR (status = 'past_due' AND grace_until < sqlc.arg(now))
+    FOR UPDATE SKIP LOCKED;
+
+-- name: CreateSubscriptionEvent :one
+INSERT INTO subscription_events(
+    id,
+    created_at,
+    subscription_id,
+    event,
+    status
+)
+    VALUES($1, $2, $3, $4, $5)
+    RETURNING *;
+
+-- name: GetSubscriptionEvents :many
+SELECT * FROM subscription_events
+    WHERE subscription_id=$1
+    ORDER BY created_at ASC;
+
+-- name: DeleteAllSubscriptionEvents :exec
+DELETE FROM subscription_events;
+
+-- name: DeleteAllSubscriptions :exec
+DELETE FROM subscriptions;
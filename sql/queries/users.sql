@@ -19,6 +19,10 @@ SELECT count(*) FROM users;
 SELECT * FROM users
     WHERE email=$1;
 
+-- name: GetUsersByEmails :many
+SELECT * FROM users
+    WHERE lower(email) = ANY(sqlc.arg(emails)::text[]);
+
 -- name: GetUserById :one
 SELECT * FROM users
     WHERE id=$1;
@@ -36,4 +40,70 @@ UPDATE users
     SET is_chirpy_red=$2,
         updated_at=$3
     WHERE id=$1
-    RETURNING *;
\ No newline at end of file
+    RETURNING *;
+
+-- name: SetUserModerator :one
+UPDATE users
+    SET is_moderator=$2,
+        updated_at=$3
+    WHERE id=$1
+    RETURNING *;
+
+-- name: SuspendUser :one
+UPDATE users
+    SET suspended_until=$2,
+        suspension_reason=$3,
+        updated_at=$4
+    WHERE id=$1
+    RETURNING *;
+
+-- name: BanUser :one
+UPDATE users
+    SET banned_at=$2,
+        suspension_reason=$3,
+        updated_at=$4
+    WHERE id=$1
+    RETURNING *;
+
+-- name: UnsuspendUser :one
+UPDATE users
+    SET suspended_until=NULL,
+        banned_at=NULL,
+        suspension_reason='',
+        updated_at=$2
+    WHERE id=$1
+    RETURNING *;
+
+-- name: DowngradeUserFromRed :one
+UPDATE users
+    SET is_chirpy_red=false,
+        updated_at=$2
+    WHERE id=$1
+    RETURNING *;
+
+-- name: SetUserDMsOpen :one
+UPDATE users
+    SET dms_open=$2,
+        updated_at=$3
+    WHERE id=$1
+    RETURNING *;
+
+-- name: SetUserProtected :one
+UPDATE users
+    SET is_protected=$2,
+        updated_at=$3
+    WHERE id=$1
+    RETURNING *;
+
+-- name: SetUserSensitiveContent :one
+UPDATE users
+    SET sensitive_content=$2,
+        updated_at=$3
+    WHERE id=$1
+    RETURNING *;
+
+-- name: LockUser :exec
+-- Serializes changes that check a per-user limit before applying it.
+SELECT id FROM users
+    WHERE id=$1
+    FOR UPDATE;
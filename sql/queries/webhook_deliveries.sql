@@ -0,0 +1,57 @@
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries(
+    id,
+    created_at,
+    updated_at,
+    endpoint_id,
+    event,
+    payload,
+    next_attempt_at
+)
+    VALUES($1, $2, $3, $4, $5, $6, $7)
+    RETURNING *;
+
+-- name: ClaimWebhookDeliveries :many
+-- Claimed deliveries are leased by pushing next_attempt_at forward, so other
+-- workers skip them while they are being sent.
+UPDATE webhook_deliveries
+    SET next_attempt_at = sqlc.arg(lease_until),
+        updated_at = sqlc.arg(now)
+    WHERE id IN (
+        SELECT id FROM webhook_deliveries
+            WHERE status = 'pending' AND next_attempt_at <= sqlc.arg(now)
+            ORDER BY next_attempt_at ASC
+            LIMIT sqlc.arg(max_deliveries)
+            FOR UPDATE SKIP LOCKED
+    )
+    RETURNING *;
+
+-- name: RecordWebhookDeliveryAttempt :one
+UPDATE webhook_deliveries
+    SET status=$2,
+        attempts=$3,
+        next_attempt_at=$4,
+        last_status_code=$5,
+        last_error=$6,
+        delivered_at=$7,
+        updated_at=$8
+    WHERE id=$1
+    RETURNING *;
+
+-- name: RetryWebhookDelivery :one
+UPDATE webhook_deliveries
+    SET status='pending',
+        attempts=0,
+        next_attempt_at=$2,
+        updated_at=$2
+    WHERE id=$1 AND endpoint_id=$3 AND status='dead'
+    RETURNING *;
+
+-- name: GetWebhookDeliveriesByEndpointId :many
+SELECT * FROM webhook_deliveries
+    WHERE endpoint_id=$1
+    ORDER BY created_at DESC
+    LIMIT $2;
+
+-- name: DeleteAllWebhookDeliveries :exec
+DELETE FROM webhook_deliveries;
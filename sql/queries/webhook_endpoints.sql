@@ -0,0 +1,37 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints(
+    id,
+    created_at,
+    updated_at,
+    owner_id,
+    scope,
+    app_name,
+    url,
+    secret,
+    events
+)
+    VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    RETURNING *;
+
+-- name: GetWebhookEndpointById :one
+SELECT * FROM webhook_endpoints
+    WHERE id=$1;
+
+-- name: GetWebhookEndpointsByOwnerId :many
+SELECT * FROM webhook_endpoints
+    WHERE owner_id=$1
+    ORDER BY created_at ASC;
+
+-- name: GetWebhookEndpointsForEvent :many
+-- User endpoints only hear about their owner's resources, app endpoints hear
+-- about everyone's.
+SELECT * FROM webhook_endpoints
+    WHERE (scope = 'app' OR owner_id = sqlc.arg(user_id)::uuid)
+        AND sqlc.arg(event)::text = ANY(events);
+
+-- name: DeleteWebhookEndpoint :execrows
+DELETE FROM webhook_endpoints
+    WHERE id=$1 AND owner_id=$2;
+
+-- name: DeleteAllWebhookEndpoints :exec
+DELETE FROM webhook_endpoints;
@@ -0,0 +1,56 @@
+-- name: RecordWebhookEvent :one
+-- Redeliveries of a known delivery ID only bump the attempt counter, the
+-- stored payload is kept as first received.
+INSERT INTO webhook_events(
+    id,
+    created_at,
+    updated_at,
+    source,
+    delivery_id,
+    event,
+    payload
+)
+    VALUES($1, $2, $3, $4, $5, $6, $7)
+    ON CONFLICT (source, delivery_id) DO UPDATE
+        SET attempts = webhook_events.attempts + 1,
+            updated_at = EXCLUDED.updated_at
+    RETURNING *;
+
+-- name: GetWebhookEventById :one
+SELECT * FROM webhook_events
+    WHERE id=$1;
+
+-- name: LockWebhookEvent :one
+SELECT * FROM webhook_events
+    WHERE id=$1
+    FOR UPDATE;
+
+-- name: GetWebhookEvents :many
+SELECT * FROM webhook_events
+    ORDER BY created_at DESC
+    LIMIT $1;
+
+-- name: GetWebhookEventsByStatus :many
+SELECT * FROM webhook_events
+    WHERE status=$1
+    ORDER BY created_at DESC
+    LIMIT $2;
+
+-- name: MarkWebhookEvent :one
+UPDATE webhook_events
+    SET status=$2,
+        error=$3,
+        processed_at=$4,
+        updated_at=$5
+    WHERE id=$1
+    RETURNING *;
+
+-- name: IncrementWebhookEventAttempts :one
+UPDATE webhook_events
+    SET attempts = attempts + 1,
+        updated_at=$2
+    WHERE id=$1
+    RETURNING *;
+
+-- name: DeleteAllWebhookEvents :exec
+DELETE FROM webhook_events;
@@ -0,0 +1,50 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN is_moderator BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users
+    ADD COLUMN suspended_until TIMESTAMP;
+
+ALTER TABLE chirps
+    ADD COLUMN hidden_at TIMESTAMP;
+
+CREATE TABLE reports(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    chirp_id UUID
+        REFERENCES chirps(id)
+        ON DELETE SET NULL,
+    reporter_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open',
+    moderator_id UUID
+        REFERENCES users(id)
+        ON DELETE SET NULL,
+    resolution TEXT,
+    resolved_at TIMESTAMP,
+    UNIQUE (chirp_id, reporter_id)
+);
+
+CREATE TABLE moderation_actions(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    moderator_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    report_id UUID,
+    chirp_id UUID,
+    user_id UUID,
+    notes TEXT NOT NULL DEFAULT ''
+);
+
+-- +goose Down
+DROP TABLE moderation_actions;
+DROP TABLE reports;
+ALTER TABLE chirps
+    DROP COLUMN hidden_at;
+ALTER TABLE users
+    DROP COLUMN suspended_until;
+ALTER TABLE users
+    DROP COLUMN is_moderator;
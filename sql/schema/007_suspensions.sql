@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN banned_at TIMESTAMP;
+ALTER TABLE users
+    ADD COLUMN suspension_reason TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN suspension_reason;
+ALTER TABLE users
+    DROP COLUMN banned_at;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE filter_words(
+    word TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    action TEXT NOT NULL
+);
+
+-- Reports raised by the profanity filter have no reporting user.
+ALTER TABLE reports
+    ALTER COLUMN reporter_id DROP NOT NULL;
+
+-- +goose Down
+DELETE FROM reports WHERE reporter_id IS NULL;
+ALTER TABLE reports
+    ALTER COLUMN reporter_id SET NOT NULL;
+DROP TABLE filter_words;
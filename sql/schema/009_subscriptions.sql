@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE subscriptions(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    current_period_end TIMESTAMP NOT NULL,
+    grace_until TIMESTAMP,
+    ended_at TIMESTAMP,
+    UNIQUE (user_id)
+);
+
+CREATE TABLE subscription_events(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    subscription_id UUID NOT NULL
+        REFERENCES subscriptions(id)
+        ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    status TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE subscription_events;
+DROP TABLE subscriptions;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE webhook_events(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    source TEXT NOT NULL,
+    delivery_id TEXT NOT NULL,
+    event TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 1,
+    processed_at TIMESTAMP,
+    UNIQUE (source, delivery_id)
+);
+
+-- +goose Down
+DROP TABLE webhook_events;
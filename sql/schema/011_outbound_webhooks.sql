@@ -0,0 +1,38 @@
+-- +goose Up
+CREATE TABLE webhook_endpoints(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    owner_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    scope TEXT NOT NULL DEFAULT 'user',
+    app_name TEXT NOT NULL DEFAULT '',
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL
+);
+
+CREATE TABLE webhook_deliveries(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    endpoint_id UUID NOT NULL
+        REFERENCES webhook_endpoints(id)
+        ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_status_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE webhook_deliveries;
+DROP TABLE webhook_endpoints;
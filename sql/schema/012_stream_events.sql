@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE stream_events(
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    type TEXT NOT NULL,
+    payload TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE stream_events;
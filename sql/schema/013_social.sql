@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE follows(
+    follower_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    followee_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (follower_id, followee_id)
+);
+
+CREATE TABLE likes(
+    user_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    chirp_id UUID NOT NULL
+        REFERENCES chirps(id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE TABLE rechirps(
+    user_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    chirp_id UUID NOT NULL
+        REFERENCES chirps(id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+ALTER TABLE chirps
+    ADD COLUMN reply_to_id UUID
+        REFERENCES chirps(id)
+        ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE chirps
+    DROP COLUMN reply_to_id;
+DROP TABLE rechirps;
+DROP TABLE likes;
+DROP TABLE follows;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE notifications(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    actor_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    chirp_id UUID
+        REFERENCES chirps(id)
+        ON DELETE CASCADE,
+    read_at TIMESTAMP
+);
+
+CREATE INDEX notifications_user_idx ON notifications (user_id, created_at DESC);
+
+CREATE TABLE notification_preferences(
+    user_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    muted BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, type)
+);
+
+-- +goose Down
+DROP TABLE notification_preferences;
+DROP TABLE notifications;
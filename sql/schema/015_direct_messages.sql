@@ -0,0 +1,39 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN dms_open BOOLEAN NOT NULL DEFAULT false;
+
+-- Each pair of users has one conversation, stored with the lower id first.
+CREATE TABLE conversations(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_one_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    user_two_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    UNIQUE (user_one_id, user_two_id),
+    CHECK (user_one_id < user_two_id)
+);
+
+CREATE TABLE messages(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    conversation_id UUID NOT NULL
+        REFERENCES conversations(id)
+        ON DELETE CASCADE,
+    sender_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    read_at TIMESTAMP
+);
+
+CREATE INDEX messages_conversation_idx ON messages (conversation_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE messages;
+DROP TABLE conversations;
+ALTER TABLE users
+    DROP COLUMN dms_open;
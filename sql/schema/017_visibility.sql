@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE chirps
+    ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public';
+
+ALTER TABLE users
+    ADD COLUMN is_protected BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN is_protected;
+ALTER TABLE chirps
+    DROP COLUMN visibility;
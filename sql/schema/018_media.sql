@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE media(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    -- Unattached and orphaned media is cleaned up by a background job.
+    chirp_id UUID
+        REFERENCES chirps(id)
+        ON DELETE SET NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    content_type TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    storage_key TEXT NOT NULL,
+    thumbnail_key TEXT NOT NULL,
+    thumbnail_type TEXT NOT NULL
+);
+
+CREATE INDEX media_chirp_idx ON media (chirp_id, position);
+
+-- +goose Down
+DROP TABLE media;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Chirps that aren't published yet. A row is deleted in the same transaction
+-- that inserts its chirp, so each one is published at most once.
+CREATE TABLE scheduled_chirps(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    -- Not a foreign key: a reply whose parent is gone fails to publish
+    -- instead of silently becoming a top-level chirp.
+    reply_to_id UUID,
+    visibility TEXT NOT NULL,
+    media_ids UUID[] NOT NULL DEFAULT '{}',
+    -- scheduled, draft or failed. Drafts have no publish_at.
+    status TEXT NOT NULL,
+    publish_at TIMESTAMP,
+    failure_reason TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX scheduled_chirps_due_idx ON scheduled_chirps (publish_at)
+    WHERE status = 'scheduled';
+CREATE INDEX scheduled_chirps_user_idx ON scheduled_chirps (user_id, status);
+
+-- +goose Down
+DROP TABLE scheduled_chirps;
@@ -0,0 +1,39 @@
+-- +goose Up
+CREATE TABLE polls(
+    chirp_id UUID PRIMARY KEY
+        REFERENCES chirps(id)
+        ON DELETE CASCADE,
+    closes_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE poll_options(
+    id UUID PRIMARY KEY,
+    chirp_id UUID NOT NULL
+        REFERENCES polls(chirp_id)
+        ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    label TEXT NOT NULL,
+    UNIQUE (chirp_id, position)
+);
+
+-- One vote per user and poll.
+CREATE TABLE poll_votes(
+    chirp_id UUID NOT NULL
+        REFERENCES polls(chirp_id)
+        ON DELETE CASCADE,
+    user_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    option_id UUID NOT NULL
+        REFERENCES poll_options(id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, user_id)
+);
+
+CREATE INDEX poll_votes_option_idx ON poll_votes (option_id);
+
+-- +goose Down
+DROP TABLE poll_votes;
+DROP TABLE poll_options;
+DROP TABLE polls;
@@ -0,0 +1,39 @@
+-- +goose Up
+CREATE TABLE bookmarks(
+    user_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    chirp_id UUID NOT NULL
+        REFERENCES chirps(id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+-- Lists are private to their owner.
+CREATE TABLE lists(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE list_members(
+    list_id UUID NOT NULL
+        REFERENCES lists(id)
+        ON DELETE CASCADE,
+    user_id UUID NOT NULL
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (list_id, user_id)
+);
+
+-- +goose Down
+DROP TABLE list_members;
+DROP TABLE lists;
+DROP TABLE bookmarks;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Pinned chirps are shown ahead of the rest of their author's feed.
+ALTER TABLE chirps
+    ADD COLUMN pinned_at TIMESTAMP;
+
+CREATE INDEX chirps_pinned_idx ON chirps (user_id, pinned_at)
+    WHERE pinned_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX chirps_pinned_idx;
+ALTER TABLE chirps
+    DROP COLUMN pinned_at;
@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE chirps
+    ADD COLUMN content_warning TEXT NOT NULL DEFAULT '',
+    ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE scheduled_chirps
+    ADD COLUMN content_warning TEXT NOT NULL DEFAULT '',
+    ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT false;
+
+-- How listings treat chirps with a content warning or sensitive flag:
+-- 'collapse' or 'hide'.
+ALTER TABLE users
+    ADD COLUMN sensitive_content TEXT NOT NULL DEFAULT 'collapse';
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN sensitive_content;
+ALTER TABLE scheduled_chirps
+    DROP COLUMN content_warning,
+    DROP COLUMN sensitive;
+ALTER TABLE chirps
+    DROP COLUMN content_warning,
+    DROP COLUMN sensitive;
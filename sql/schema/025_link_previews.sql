@@ -0,0 +1,28 @@
+-- +goose Up
+-- Previews are cached per URL and shared by every chirp that links to it.
+CREATE TABLE link_previews(
+    url TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    site_name TEXT NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    fetched_at TIMESTAMP
+);
+
+CREATE INDEX link_previews_due_idx ON link_previews (next_attempt_at)
+    WHERE status = 'pending';
+
+-- The link shown as the chirp's preview card, or ''.
+ALTER TABLE chirps
+    ADD COLUMN link_url TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE chirps
+    DROP COLUMN link_url;
+DROP TABLE link_previews;
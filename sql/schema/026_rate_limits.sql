@@ -0,0 +1,12 @@
+-- +goose Up
+-- Token buckets shared by every instance when RATE_LIMIT_STORE=postgres.
+-- Losing them in a crash only resets everyone's budget, so they're unlogged.
+CREATE UNLOGGED TABLE rate_limit_buckets(
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    allowed BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE rate_limit_buckets;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Responses to POST requests sent with an Idempotency-Key, replayed when the
+-- request is retried. scope is the user the key belongs to, or ip:<address>
+-- for anonymous callers.
+CREATE TABLE idempotency_keys(
+    scope TEXT NOT NULL,
+    key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    content_type TEXT NOT NULL DEFAULT '',
+    response_body BYTEA NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys(created_at);
+
+-- +goose Down
+DROP TABLE idempotency_keys;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Chirpy Red users from before subscriptions were tracked get an active
+-- subscription for 30 days, the default SUBSCRIPTION_PERIOD, so Polka's
+-- renewals, cancellations and downgrades apply to them. Migrations can't read
+-- the environment; deployments with a different period should move
+-- current_period_end of the 'subscription.backfilled' subscriptions.
+WITH backfilled AS (
+    INSERT INTO subscriptions(id, created_at, updated_at, user_id, status, started_at, current_period_end)
+        SELECT gen_random_uuid(), now() AT TIME ZONE 'utc', now() AT TIME ZONE 'utc', users.id, 'active',
+            users.updated_at, now() AT TIME ZONE 'utc' + INTERVAL '30 days'
+        FROM users
+        WHERE users.is_chirpy_red
+            AND NOT EXISTS (SELECT 1 FROM subscriptions WHERE subscriptions.user_id = users.id)
+        RETURNING id, created_at, status
+)
+INSERT INTO subscription_events(id, created_at, subscription_id, event, status)
+    SELECT gen_random_uuid(), created_at, id, 'subscription.backfilled', status
+    FROM backfilled;
+
+-- +goose Down
+-- Backfilled subscriptions can't be told apart from real ones once they've
+-- been renewed, so they're left in place.
+SELECT 1;
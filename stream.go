@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/chirptext"
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/aramirez3/chirpy/internal/events"
+	"github.com/google/uuid"
+)
+
+const (
+	streamChannel           = "chirpy_stream"
+	streamBuffer            = 64
+	streamBacklog           = 500
+	streamHeartbeat         = 15 * time.Second
+	streamRetention         = 24 * time.Hour
+	streamRetentionInterval = time.Hour
+)
+
+type streamFilter struct {
+	authors map[uuid.UUID]bool
+	hashtag string
+	// hidden holds authors the viewer blocked, muted or is blocked by.
+	hidden map[uuid.UUID]bool
+}
+
+// recordStreamEvent stores a chirp event and NOTIFYs every instance about it.
+// Inside a transaction the notification is held back until commit. The stream
+// is readable by anyone, so only public chirps are recorded.
+func recordStreamEvent(ctx context.Context, q *database.Queries, eventType string, chirp Chirp) error {
+	if chirp.Visibility != visibilityPublic {
+		return nil
+	}
+	payload, err := json.Marshal(chirp)
+	if err != nil {
+		return err
+	}
+	dbEvent, err := q.CreateStreamEvent(ctx, database.CreateStreamEventParams{
+		CreatedAt: time.Now().UTC(),
+		Type:      eventType,
+		Payload:   string(payload),
+	})
+	if err != nil {
+		return err
+	}
+	return q.NotifyChannel(ctx, database.NotifyChannelParams{
+		Channel: streamChannel,
+		Payload: strconv.FormatInt(dbEvent.ID, 10),
+	})
+}
+
+func (cfg *apiConfig) publishStreamEvent(ctx context.Context, eventType string, chirp Chirp) {
+	err := recordStreamEvent(ctx, cfg.dbQueries, eventType, chirp)
+	if err != nil {
+		log.Printf("error publishing %s stream event: %s\n", eventType, err)
+	}
+}
+
+// loadStreamEvent is the events.Loader for NOTIFY payloads, which carry the
+// stream_events id.
+func (cfg *apiConfig) loadStreamEvent(ctx context.Context, payload string) (events.Event, error) {
+	id, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return events.Event{}, err
+	}
+	dbEvent, err := cfg.dbQueries.GetStreamEventById(ctx, id)
+	if err != nil {
+		return events.Event{}, err
+	}
+	return dbStreamEventToEvent(dbEvent), nil
+}
+
+// handleStream serves new and deleted chirps as Server-Sent Events. Clients
+// that reconnect with Last-Event-ID first get the events they missed.
+func (cfg *apiConfig) handleStream(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		returnErrorResponse(w, "Streaming unsupported")
+		return
+	}
+	filter, err := parseStreamFilter(req)
+	if err != nil {
+		returnErrorResponse(w, err.Error())
+		return
+	}
+	lastId := int64(0)
+	lastIdString := req.Header.Get("Last-Event-ID")
+	if lastIdString == "" {
+		lastIdString = req.URL.Query().Get("last_event_id")
+	}
+	if lastIdString != "" {
+		lastId, err = strconv.ParseInt(lastIdString, 10, 64)
+		if err != nil {
+			returnErrorResponse(w, "Invalid Last-Event-ID")
+			return
+		}
+	}
+
+	// Subscribe before reading the backlog so nothing published in between is lost.
+	sub := cfg.eventBus.Subscribe(streamBuffer)
+	defer sub.Unsubscribe()
+
+	w.Header().Set(contentType, "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if lastId > 0 {
+		backlog, err := cfg.dbQueries.GetStreamEventsAfter(req.Context(), database.GetStreamEventsAfterParams{
+			ID:    lastId,
+			Limit: streamBacklog,
+		})
+		if err != nil {
+			return
+		}
+		for _, dbEvent := range backlog {
+			event := dbStreamEventToEvent(dbEvent)
+			if filter.matches(event) {
+				writeStreamEvent(w, event)
+			}
+			lastId = event.ID
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case event, ok := <-sub.C:
+			// A closed channel means the client fell too far behind; it can
+			// reconnect with Last-Event-ID to catch up.
+			if !ok {
+				return
+			}
+			if event.ID <= lastId || !filter.matches(event) {
+				continue
+			}
+			lastId = event.ID
+			writeStreamEvent(w, event)
+			flusher.Flush()
+		}
+	}
+}
+
+func (cfg *apiConfig) runStreamRetention(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := cfg.dbQueries.DeleteStreamEventsBefore(ctx, time.Now().UTC().Add(-streamRetention))
+			if err != nil {
+				log.Printf("error pruning stream events: %s\n", err)
+			}
+		}
+	}
+}
+
+func parseStreamFilter(req *http.Request) (streamFilter, error) {
+	return newStreamFilter(req.URL.Query().Get("author_id"), req.URL.Query().Get("hashtag"))
+}
+
+// newStreamFilter takes a comma separated list of author ids and an optional
+// hashtag, with or without the leading "#".
+func newStreamFilter(authorIds, hashtag string) (streamFilter, error) {
+	filter := streamFilter{
+		authors: map[uuid.UUID]bool{},
+		hashtag: strings.ToLower(strings.TrimPrefix(hashtag, "#")),
+	}
+	for _, author := range strings.Split(authorIds, ",") {
+		if author == "" {
+			continue
+		}
+		authorId, err := uuid.Parse(author)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid author_id")
+		}
+		filter.authors[authorId] = true
+	}
+	return filter, nil
+}
+
+func (f streamFilter) matches(event events.Event) bool {
+	if len(f.authors) == 0 && f.hashtag == "" && len(f.hidden) == 0 {
+		return true
+	}
+	chirp := Chirp{}
+	err := json.Unmarshal(event.Data, &chirp)
+	if err != nil {
+		return false
+	}
+	if f.hidden[chirp.UserId] {
+		return false
+	}
+	if len(f.authors) > 0 && !f.authors[chirp.UserId] {
+		return false
+	}
+	if f.hashtag == "" {
+		return true
+	}
+	for _, tag := range chirptext.Hashtags(chirp.Body) {
+		if tag == f.hashtag {
+			return true
+		}
+	}
+	return false
+}
+
+func writeStreamEvent(w http.ResponseWriter, event events.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
+
+func dbStreamEventToEvent(e database.StreamEvent) events.Event {
+	return events.Event{
+		ID:   e.ID,
+		Type: e.Type,
+		Data: []byte(e.Payload),
+	}
+}
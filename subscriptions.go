@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type Subscription struct {
+	Id               uuid.UUID           `json:"id"`
+	Status           string              `json:"status"`
+	StartedAt        time.Time           `json:"started_at"`
+	CurrentPeriodEnd time.Time           `json:"current_period_end"`
+	GraceUntil       *time.Time          `json:"grace_until,omitempty"`
+	EndedAt          *time.Time          `json:"ended_at,omitempty"`
+	History          []SubscriptionEvent `json:"history"`
+}
+
+type SubscriptionEvent struct {
+	Event     string    `json:"event"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const (
+	subscriptionActive    = "active"
+	subscriptionPastDue   = "past_due"
+	subscriptionCancelled = "cancelled"
+	subscriptionExpired   = "expired"
+)
+
+const (
+	subscriptionExpiredEvent   = "subscription.expired"
+	defaultSubscriptionPeriod  = 30 * 24 * time.Hour
+	defaultGracePeriod         = 3 * 24 * time.Hour
+	subscriptionExpiryInterval = 10 * time.Minute
+)
+
+var errNoSubscription = errors.New("user has no subscription")
+
+// applyPolkaEvent moves a user's subscription through its lifecycle. Users keep
+// Chirpy Red while a subscription is active, past due (until the grace period
+// runs out) or cancelled (until the paid period ends); only downgrades and
+// the expiry job remove it.
+func (cfg *apiConfig) applyPolkaEvent(ctx context.Context, qtx *database.Queries, event string, data PolkaRequestData) error {
+	now := time.Now().UTC()
+	periodEnd := now.Add(cfg.SubscriptionPeriod)
+	if data.PeriodEnd != nil {
+		periodEnd = data.PeriodEnd.UTC()
+	}
+
+	if event == polkaUserUpgraded {
+		sub, err := qtx.UpsertSubscription(ctx, database.UpsertSubscriptionParams{
+			ID:               uuid.New(),
+			CreatedAt:        now,
+			UpdatedAt:        now,
+			UserID:           data.UserId,
+			Status:           subscriptionActive,
+			StartedAt:        now,
+			CurrentPeriodEnd: periodEnd,
+		})
+		if err != nil {
+			return err
+		}
+		err = setChirpyRed(ctx, qtx, data.UserId, true)
+		if err != nil {
+			return err
+		}
+		return recordSubscriptionEvent(ctx, qtx, sub, event)
+	}
+
+	sub, err := qtx.GetSubscriptionByUserId(ctx, data.UserId)
+	if errors.Is(err, sql.ErrNoRows) && event == polkaUserDowngraded {
+		// Nothing to end, but a downgrade still has to take Chirpy Red away.
+		return setChirpyRed(ctx, qtx, data.UserId, false)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return errNoSubscription
+	}
+	if err != nil {
+		return err
+	}
+
+	params := database.UpdateSubscriptionParams{
+		ID:               sub.ID,
+		Status:           sub.Status,
+		CurrentPeriodEnd: sub.CurrentPeriodEnd,
+		GraceUntil:       sub.GraceUntil,
+		EndedAt:          sub.EndedAt,
+		UpdatedAt:        now,
+	}
+	red := true
+	switch event {
+	case polkaSubscriptionRenewed:
+		if data.PeriodEnd == nil && sub.CurrentPeriodEnd.After(now) {
+			periodEnd = sub.CurrentPeriodEnd.Add(cfg.SubscriptionPeriod)
+		}
+		params.Status = subscriptionActive
+		params.CurrentPeriodEnd = periodEnd
+		params.GraceUntil = sql.NullTime{}
+		params.EndedAt = sql.NullTime{}
+	case polkaPaymentFailed:
+		params.Status = subscriptionPastDue
+		params.GraceUntil = sql.NullTime{Time: now.Add(cfg.GracePeriod), Valid: true}
+	case polkaSubscriptionCancelled:
+		params.Status = subscriptionCancelled
+	case polkaUserDowngraded:
+		params.Status = subscriptionExpired
+		params.EndedAt = sql.NullTime{Time: now, Valid: true}
+		red = false
+	}
+
+	updated, err := qtx.UpdateSubscription(ctx, params)
+	if err != nil {
+		return err
+	}
+	err = setChirpyRed(ctx, qtx, data.UserId, red)
+	if err != nil {
+		return err
+	}
+	return recordSubscriptionEvent(ctx, qtx, updated, event)
+}
+
+// expireLapsedSubscriptions downgrades every user whose subscription ran out.
+// Rows are locked with SKIP LOCKED so several instances can run the job at once.
+func (cfg *apiConfig) expireLapsedSubscriptions(ctx context.Context) (int, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	now := time.Now().UTC()
+	lapsed, err := qtx.GetLapsedSubscriptions(ctx, database.GetLapsedSubscriptionsParams{
+		ActiveLapsedBefore: now.Add(-cfg.GracePeriod),
+		Now:                now,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, sub := range lapsed {
+		updated, err := qtx.UpdateSubscription(ctx, database.UpdateSubscriptionParams{
+			ID:               sub.ID,
+			Status:           subscriptionExpired,
+			CurrentPeriodEnd: sub.CurrentPeriodEnd,
+			GraceUntil:       sub.GraceUntil,
+			EndedAt:          sql.NullTime{Time: now, Valid: true},
+			UpdatedAt:        now,
+		})
+		if err != nil {
+			return 0, err
+		}
+		err = setChirpyRed(ctx, qtx, sub.UserID, false)
+		if err != nil {
+			return 0, err
+		}
+		err = recordSubscriptionEvent(ctx, qtx, updated, subscriptionExpiredEvent)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(lapsed), tx.Commit()
+}
+
+func (cfg *apiConfig) runSubscriptionExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := cfg.expireLapsedSubscriptions(ctx)
+			if err != nil {
+				log.Printf("error expiring subscriptions: %s\n", err)
+				continue
+			}
+			if expired > 0 {
+				log.Printf("expired %d subscriptions\n", expired)
+			}
+		}
+	}
+}
+
+// setChirpyRed updates the user's tier and notifies webhook subscribers when
+// it actually changed.
+func setChirpyRed(ctx context.Context, qtx *database.Queries, userId uuid.UUID, red bool) error {
+	dbUser, err := qtx.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if dbUser.IsChirpyRed.Bool == red {
+		return nil
+	}
+
+	event := eventUserUpgraded
+	if red {
+		dbUser, err = qtx.UpgradeUserToRed(ctx, database.UpgradeUserToRedParams{
+			ID: userId,
+			IsChirpyRed: sql.NullBool{
+				Bool:  true,
+				Valid: true,
+			},
+			UpdatedAt: time.Now().UTC(),
+		})
+	} else {
+		event = eventUserDowngraded
+		dbUser, err = qtx.DowngradeUserFromRed(ctx, database.DowngradeUserFromRedParams{
+			ID:        userId,
+			UpdatedAt: time.Now().UTC(),
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return enqueueWebhookEvent(ctx, qtx, event, userId, User{
+		Id:          dbUser.ID,
+		CreatedAt:   dbUser.CreatedAt,
+		UpdatedAt:   dbUser.UpdatedAt,
+		Email:       dbUser.Email,
+		IsChirpyRed: dbUser.IsChirpyRed.Bool,
+	})
+}
+
+func recordSubscriptionEvent(ctx context.Context, qtx *database.Queries, sub database.Subscription, event string) error {
+	_, err := qtx.CreateSubscriptionEvent(ctx, database.CreateSubscriptionEventParams{
+		ID:             uuid.New(),
+		CreatedAt:      time.Now().UTC(),
+		SubscriptionID: sub.ID,
+		Event:          event,
+		Status:         sub.Status,
+	})
+	return err
+}
+
+func (cfg *apiConfig) handleGetSubscription(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	sub, err := cfg.dbQueries.GetSubscriptionByUserId(req.Context(), dbUser.ID)
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+	events, err := cfg.dbQueries.GetSubscriptionEvents(req.Context(), sub.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	respBody, _ := encodeJson(dbSubscriptionToResponse(sub, events))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func dbSubscriptionToResponse(s database.Subscription, events []database.SubscriptionEvent) Subscription {
+	response := Subscription{
+		Id:               s.ID,
+		Status:           s.Status,
+		StartedAt:        s.StartedAt,
+		CurrentPeriodEnd: s.CurrentPeriodEnd,
+		History:          []SubscriptionEvent{},
+	}
+	if s.GraceUntil.Valid {
+		response.GraceUntil = &s.GraceUntil.Time
+	}
+	if s.EndedAt.Valid {
+		response.EndedAt = &s.EndedAt.Time
+	}
+	for _, e := range events {
+		response.History = append(response.History, SubscriptionEvent{
+			Event:     e.Event,
+			Status:    e.Status,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	return response
+}
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aramirez3/chirpy/internal/auth"
+	"github.com/aramirez3/chirpy/internal/database"
+	"github.com/aramirez3/chirpy/internal/linkpreview"
+	"github.com/aramirez3/chirpy/internal/webhook"
+	"github.com/google/uuid"
+)
+
+type WebhookEndpoint struct {
+	Id        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Scope     string    `json:"scope"`
+	AppName   string    `json:"app_name,omitempty"`
+	Url       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+}
+
+type WebhookEndpointRequest struct {
+	Url     string   `json:"url"`
+	Events  []string `json:"events"`
+	AppName string   `json:"app_name"`
+}
+
+type WebhookDelivery struct {
+	Id             uuid.UUID       `json:"id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode int32           `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// WebhookPayload is the JSON body sent to subscribers.
+type WebhookPayload struct {
+	Id        uuid.UUID `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+const (
+	eventChirpCreated   = "chirp.created"
+	eventChirpDeleted   = "chirp.deleted"
+	eventChirpRestored  = "chirp.restored"
+	eventUserCreated    = "user.created"
+	eventUserUpdated    = "user.updated"
+	eventUserUpgraded   = "user.upgraded"
+	eventUserDowngraded = "user.downgraded"
+)
+
+const (
+	webhookScopeUser = "user"
+	webhookScopeApp  = "app"
+)
+
+const (
+	deliveryPending   = "pending"
+	deliveryDelivered = "delivered"
+	deliveryDead      = "dead"
+)
+
+const (
+	webhookWorkerInterval = 5 * time.Second
+	webhookLease          = time.Minute
+	webhookBatchSize      = 20
+)
+
+var webhookEvents = map[string]bool{
+	eventChirpCreated:   true,
+	eventChirpDeleted:   true,
+	eventChirpRestored:  true,
+	eventUserCreated:    true,
+	eventUserUpdated:    true,
+	eventUserUpgraded:   true,
+	eventUserDowngraded: true,
+}
+
+const webhookTimeout = 10 * time.Second
+
+// webhookClient only delivers to public addresses, checked when connecting,
+// and doesn't follow redirects, which could lead anywhere. A redirect counts
+// as a failed delivery.
+var webhookClient = &http.Client{
+	Timeout:   webhookTimeout,
+	Transport: linkpreview.PublicTransport(webhookTimeout),
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// enqueueWebhookEvent queues a delivery for every endpoint subscribed to event
+// for userId. Pass a transaction's queries to queue it atomically with the change.
+func enqueueWebhookEvent(ctx context.Context, q *database.Queries, event string, userId uuid.UUID, data any) error {
+	endpoints, err := q.GetWebhookEndpointsForEvent(ctx, database.GetWebhookEndpointsForEventParams{
+		UserID: userId,
+		Event:  event,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, endpoint := range endpoints {
+		if endpoint.Scope == webhookScopeApp && !publicWebhookData(data) {
+			continue
+		}
+		deliveryId := uuid.New()
+		payload, err := json.Marshal(WebhookPayload{
+			Id:        deliveryId,
+			Event:     event,
+			CreatedAt: now,
+			Data:      data,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+			ID:            deliveryId,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			EndpointID:    endpoint.ID,
+			Event:         event,
+			Payload:       string(payload),
+			NextAttemptAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publicWebhookData reports whether an event's data may go to app endpoints,
+// which hear about everyone. Like the stream, they only get public chirps;
+// users carry their email, so user events only go to the user's own endpoints.
+func publicWebhookData(data any) bool {
+	switch data := data.(type) {
+	case Chirp:
+		return data.Visibility == visibilityPublic
+	case User:
+		return false
+	}
+	return true
+}
+
+// publishWebhookEvent is enqueueWebhookEvent for handlers that aren't in a
+// transaction; failures are logged and don't fail the request.
+func (cfg *apiConfig) publishWebhookEvent(ctx context.Context, event string, userId uuid.UUID, data any) {
+	err := enqueueWebhookEvent(ctx, cfg.dbQueries, event, userId, data)
+	if err != nil {
+		log.Printf("error queueing %s webhooks: %s\n", event, err)
+	}
+}
+
+func (cfg *apiConfig) runWebhookDeliveries(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := cfg.sendDueWebhooks(ctx)
+			if err != nil {
+				log.Printf("error sending webhooks: %s\n", err)
+			}
+		}
+	}
+}
+
+func (cfg *apiConfig) sendDueWebhooks(ctx context.Context) error {
+	now := time.Now().UTC()
+	deliveries, err := cfg.dbQueries.ClaimWebhookDeliveries(ctx, database.ClaimWebhookDeliveriesParams{
+		LeaseUntil:    now.Add(webhookLease),
+		Now:           now,
+		MaxDeliveries: webhookBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+	for _, delivery := range deliveries {
+		err = cfg.sendWebhook(ctx, delivery)
+		if err != nil {
+			log.Printf("error recording webhook delivery %s: %s\n", delivery.ID, err)
+		}
+	}
+	return nil
+}
+
+// sendWebhook makes one delivery attempt. Failures are retried with
+// exponential backoff until webhook.MaxAttempts, then dead-lettered.
+func (cfg *apiConfig) sendWebhook(ctx context.Context, delivery database.WebhookDelivery) error {
+	endpoint, err := cfg.dbQueries.GetWebhookEndpointById(ctx, delivery.EndpointID)
+	if err != nil {
+		return err
+	}
+
+	statusCode, sendErr := webhook.Deliver(ctx, webhookClient, endpoint.Url, endpoint.Secret, delivery.ID.String(), delivery.Event, []byte(delivery.Payload))
+	now := time.Now().UTC()
+	params := database.RecordWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         deliveryDelivered,
+		Attempts:       delivery.Attempts + 1,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: int32(statusCode),
+		DeliveredAt:    sql.NullTime{Time: now, Valid: true},
+		UpdatedAt:      now,
+	}
+	if sendErr != nil {
+		params.Status = deliveryPending
+		params.NextAttemptAt = now.Add(webhook.Backoff(int(params.Attempts)))
+		params.LastError = sendErr.Error()
+		params.DeliveredAt = sql.NullTime{}
+		if params.Attempts >= webhook.MaxAttempts {
+			params.Status = deliveryDead
+		}
+	}
+	_, err = cfg.dbQueries.RecordWebhookDeliveryAttempt(ctx, params)
+	return err
+}
+
+// publicWebhookHost turns away endpoints that obviously point inside the
+// network. Hostnames are checked again when each delivery connects.
+func publicWebhookHost(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return false
+	}
+	addr, err := netip.ParseAddr(host)
+	return err != nil || linkpreview.IsPublic(addr)
+}
+
+func (cfg *apiConfig) handleCreateWebhookEndpoint(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	payload := WebhookEndpointRequest{}
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	target, err := url.Parse(payload.Url)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" || !publicWebhookHost(target.Hostname()) {
+		returnErrorResponse(w, "Invalid webhook url")
+		return
+	}
+	if len(payload.Events) == 0 {
+		returnErrorResponse(w, "At least one event is required")
+		return
+	}
+	for _, event := range payload.Events {
+		if !webhookEvents[event] {
+			returnErrorResponse(w, "Unknown webhook event: "+event)
+			return
+		}
+	}
+
+	// App endpoints receive events for every user, so only moderators can add them.
+	scope := webhookScopeUser
+	if payload.AppName != "" {
+		if !dbUser.IsModerator {
+			returnForbidden(w)
+			return
+		}
+		scope = webhookScopeApp
+	}
+
+	secret, err := auth.MakeRefreshToken()
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	now := time.Now().UTC()
+	dbEndpoint, err := cfg.dbQueries.CreateWebhookEndpoint(req.Context(), database.CreateWebhookEndpointParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		OwnerID:   dbUser.ID,
+		Scope:     scope,
+		AppName:   payload.AppName,
+		Url:       target.String(),
+		Secret:    secret,
+		Events:    payload.Events,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	// The secret is only shown once, when the endpoint is created.
+	response := dbWebhookEndpointToResponse(dbEndpoint)
+	response.Secret = dbEndpoint.Secret
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleGetWebhookEndpoints(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	dbEndpoints, err := cfg.dbQueries.GetWebhookEndpointsByOwnerId(req.Context(), dbUser.ID)
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	response := []WebhookEndpoint{}
+	for _, e := range dbEndpoints {
+		response = append(response, dbWebhookEndpointToResponse(e))
+	}
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) handleDeleteWebhookEndpoint(w http.ResponseWriter, req *http.Request) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+
+	endpointId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	deleted, err := cfg.dbQueries.DeleteWebhookEndpoint(req.Context(), database.DeleteWebhookEndpointParams{
+		ID:      endpointId,
+		OwnerID: dbUser.ID,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	if deleted == 0 {
+		returnNotFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetWebhookDeliveries(w http.ResponseWriter, req *http.Request) {
+	endpoint, ok := cfg.ownedWebhookEndpoint(w, req)
+	if !ok {
+		return
+	}
+	limit, err := queryLimit(req, defaultListLimit, maxListLimit)
+	if err != nil {
+		returnErrorResponse(w, "Invalid limit")
+		return
+	}
+
+	dbDeliveries, err := cfg.dbQueries.GetWebhookDeliveriesByEndpointId(req.Context(), database.GetWebhookDeliveriesByEndpointIdParams{
+		EndpointID: endpoint.ID,
+		Limit:      limit,
+	})
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+	response := []WebhookDelivery{}
+	for _, d := range dbDeliveries {
+		response = append(response, dbWebhookDeliveryToResponse(d))
+	}
+	respBody, _ := encodeJson(response)
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+// handleRetryWebhookDelivery puts a dead-lettered delivery back in the queue.
+func (cfg *apiConfig) handleRetryWebhookDelivery(w http.ResponseWriter, req *http.Request) {
+	endpoint, ok := cfg.ownedWebhookEndpoint(w, req)
+	if !ok {
+		return
+	}
+	deliveryId, err := uuid.Parse(req.PathValue("delivery_id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return
+	}
+
+	dbDelivery, err := cfg.dbQueries.RetryWebhookDelivery(req.Context(), database.RetryWebhookDeliveryParams{
+		ID:            deliveryId,
+		NextAttemptAt: time.Now().UTC(),
+		EndpointID:    endpoint.ID,
+	})
+	if err != nil {
+		returnNotFound(w)
+		return
+	}
+
+	respBody, _ := encodeJson(dbWebhookDeliveryToResponse(dbDelivery))
+	w.Header().Set(contentType, plainTextContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBody)
+}
+
+func (cfg *apiConfig) ownedWebhookEndpoint(w http.ResponseWriter, req *http.Request) (database.WebhookEndpoint, bool) {
+	dbUser, ok := cfg.authenticateRequest(w, req)
+	if !ok {
+		return database.WebhookEndpoint{}, false
+	}
+	endpointId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		returnErrorResponse(w, standardError)
+		return database.WebhookEndpoint{}, false
+	}
+	endpoint, err := cfg.dbQueries.GetWebhookEndpointById(req.Context(), endpointId)
+	if err != nil || endpoint.OwnerID != dbUser.ID {
+		returnNotFound(w)
+		return database.WebhookEndpoint{}, false
+	}
+	return endpoint, true
+}
+
+func dbWebhookEndpointToResponse(e database.WebhookEndpoint) WebhookEndpoint {
+	return WebhookEndpoint{
+		Id:        e.ID,
+		CreatedAt: e.CreatedAt,
+		Scope:     e.Scope,
+		AppName:   e.AppName,
+		Url:       e.Url,
+		Events:    e.Events,
+	}
+}
+
+func dbWebhookDeliveryToResponse(d database.WebhookDelivery) WebhookDelivery {
+	response := WebhookDelivery{
+		Id:             d.ID,
+		CreatedAt:      d.CreatedAt,
+		Event:          d.Event,
+		Payload:        json.RawMessage(d.Payload),
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+	}
+	if d.Status == deliveryPending {
+		response.NextAttemptAt = &d.NextAttemptAt
+	}
+	if d.DeliveredAt.Valid {
+		response.DeliveredAt = &d.DeliveredAt.Time
+	}
+	return response
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aramirez3/chirpy/internal/linkpreview"
+	"github.com/aramirez3/chirpy/internal/webhook"
+)
+
+func TestWebhookClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("delivery reached the loopback server")
+	}))
+	defer server.Close()
+
+	_, err := webhook.Deliver(context.Background(), webhookClient, server.URL, "secret", "id", eventChirpCreated, []byte(`{}`))
+	if !errors.Is(err, linkpreview.ErrPrivateAddress) {
+		t.Errorf("expected ErrPrivateAddress, got %v", err)
+	}
+}
+
+func TestWebhookClientDoesNotFollowRedirects(t *testing.T) {
+	client := *webhookClient
+	client.Transport = nil
+	redirected := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer server.Close()
+
+	status, err := webhook.Deliver(context.Background(), &client, server.URL, "secret", "id", eventChirpCreated, []byte(`{}`))
+	if err == nil || status != http.StatusTemporaryRedirect || redirected {
+		t.Errorf("expected the redirect to fail the delivery, got %d, %v", status, err)
+	}
+}
+
+func TestPublicWebhookHost(t *testing.T) {
+	cases := map[string]bool{
+		"hooks.example.com": true,
+		"93.184.216.34":     true,
+		"localhost":         false,
+		"api.localhost":     false,
+		"127.0.0.1":         false,
+		"10.0.0.5":          false,
+		"169.254.169.254":   false,
+		"::1":               false,
+	}
+	for host, expected := range cases {
+		if got := publicWebhookHost(host); got != expected {
+			t.Errorf("publicWebhookHost(%s) = %v, expected %v", host, got, expected)
+		}
+	}
+}
+
+func TestPublicWebhookData(t *testing.T) {
+	if !publicWebhookData(Chirp{Visibility: visibilityPublic}) {
+		t.Errorf("expected public chirps to go to app endpoints")
+	}
+	if publicWebhookData(User{Email: "user@example.com"}) {
+		t.Errorf("expected users to be kept from app endpoints")
+	}
+	for _, visibility := range []string{visibilityFollowers, visibilityUnlisted, visibilityPrivate} {
+		if publicWebhookData(Chirp{Visibility: visibility}) {
+			t.Errorf("expected %s chirps to be kept from app endpoints", visibility)
+		}
+	}
+}